@@ -0,0 +1,150 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/prometheus-community/elasticsearch_exporter/config"
+)
+
+// transportWithBasicAuth adds basic auth credentials to every request. password
+// is called per request so that passwords read from files can be rotated.
+type transportWithBasicAuth struct {
+	underlyingTransport http.RoundTripper
+	username            string
+	password            func() (string, error)
+}
+
+func (t *transportWithBasicAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	password, err := t.password()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, password)
+	return t.underlyingTransport.RoundTrip(req)
+}
+
+// transportWithAPIKey adds the API key returned by apiKey to every request.
+// apiKey is called per request so that keys read from files can be rotated.
+type transportWithAPIKey struct {
+	underlyingTransport http.RoundTripper
+	apiKey              func() (string, error)
+}
+
+func (t *transportWithAPIKey) RoundTrip(req *http.Request) (*http.Response, error) {
+	apiKey, err := t.apiKey()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", fmt.Sprintf("ApiKey %s", apiKey))
+	return t.underlyingTransport.RoundTrip(req)
+}
+
+// transportWithBearerToken adds the token returned by token as bearer token to
+// every request. token is called per request so that tokens read from files
+// can be rotated.
+type transportWithBearerToken struct {
+	underlyingTransport http.RoundTripper
+	token               func() (string, error)
+}
+
+func (t *transportWithBearerToken) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.token()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.underlyingTransport.RoundTrip(req)
+}
+
+// oauth2TokenSources caches the token sources of the OAuth2 client
+// credentials flow by their settings other than the client secret, so that
+// tokens are reused across probes and only refreshed once they expire. A
+// rotated secret replaces the token source of the same settings.
+var oauth2TokenSources = struct {
+	sync.Mutex
+	m map[string]oauth2TokenSource
+}{m: make(map[string]oauth2TokenSource)}
+
+// oauth2TokenSource is a cached token source and the secret it was created
+// with.
+type oauth2TokenSource struct {
+	secret string
+	ts     oauth2.TokenSource
+}
+
+// defaultOAuth2TokenTimeout bounds token requests if no client timeout is set.
+const defaultOAuth2TokenTimeout = 10 * time.Second
+
+// newOAuth2Transport returns a transport that authenticates requests with an
+// access token obtained through the OAuth2 client credentials flow. Tokens are
+// requested through base, which carries the TLS settings tlsSettings but none
+// of the other auth modules, and time out after timeout.
+func newOAuth2Transport(transport, base http.RoundTripper, cfg *config.OAuth2Config, tlsSettings config.TLSConfig, timeout time.Duration) (http.RoundTripper, error) {
+	secret, err := cfg.ResolveClientSecret()
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	for k, v := range cfg.EndpointParams {
+		params.Set(k, v)
+	}
+	cc := clientcredentials.Config{
+		ClientID:       cfg.ClientID,
+		ClientSecret:   secret,
+		TokenURL:       cfg.TokenURL,
+		Scopes:         cfg.Scopes,
+		EndpointParams: params,
+	}
+
+	if timeout <= 0 {
+		timeout = defaultOAuth2TokenTimeout
+	}
+	key := strings.Join([]string{
+		cc.ClientID, cc.TokenURL, strings.Join(cc.Scopes, " "), params.Encode(),
+		tlsSettings.CAFile, tlsSettings.CertFile, tlsSettings.KeyFile, strconv.FormatBool(tlsSettings.InsecureSkipVerify),
+		timeout.String(),
+	}, "\x00")
+
+	oauth2TokenSources.Lock()
+	defer oauth2TokenSources.Unlock()
+	cached, ok := oauth2TokenSources.m[key]
+	if !ok || cached.secret != secret {
+		// The token source outlives the probe it was created for and is
+		// shared by all scrapes using the module, so a token request must
+		// not hang forever.
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+			Transport: base,
+			Timeout:   timeout,
+		})
+		cached = oauth2TokenSource{secret: secret, ts: cc.TokenSource(ctx)}
+		oauth2TokenSources.m[key] = cached
+	}
+	ts := cached.ts
+	return &oauth2.Transport{Source: ts, Base: transport}, nil
+}
@@ -0,0 +1,178 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/elasticsearch_exporter/config"
+)
+
+// authHeaderServer responds with the Authorization header of each request.
+func authHeaderServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func getBody(t *testing.T, hc *http.Client, u string) string {
+	t.Helper()
+	resp, err := hc.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestTransportWithBearerToken(t *testing.T) {
+	ts := authHeaderServer(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	b := &config.BearerConfig{TokenFile: tokenFile}
+	hc := &http.Client{Transport: &transportWithBearerToken{
+		underlyingTransport: http.DefaultTransport,
+		token:               b.ResolveToken,
+	}}
+
+	if got := getBody(t, hc, ts.URL); got != "Bearer first" {
+		t.Fatalf("unexpected Authorization header %q", got)
+	}
+	if err := os.WriteFile(tokenFile, []byte("second"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := getBody(t, hc, ts.URL); got != "Bearer second" {
+		t.Fatalf("expected rotated token, got %q", got)
+	}
+}
+
+func TestOAuth2Transport(t *testing.T) {
+	var tokenRequests atomic.Int32
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "es" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"t0k3n","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer idp.Close()
+	ts := authHeaderServer(t)
+
+	cfg := &config.OAuth2Config{ClientID: "exporter", ClientSecret: "s3cr3t", TokenURL: idp.URL, Scopes: []string{"es"}}
+	// Transports are created per probe; the token is shared between them.
+	for range 2 {
+		transport, err := newOAuth2Transport(http.DefaultTransport, http.DefaultTransport, cfg, config.TLSConfig{}, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := getBody(t, &http.Client{Transport: transport}, ts.URL); got != "Bearer t0k3n" {
+			t.Fatalf("unexpected Authorization header %q", got)
+		}
+	}
+	if n := tokenRequests.Load(); n != 1 {
+		t.Fatalf("expected the token to be cached, got %d token requests", n)
+	}
+}
+
+func TestOAuth2TransportSecretRotation(t *testing.T) {
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, secret, _ := r.BasicAuth()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":3600}`, "token-"+secret)
+	}))
+	defer idp.Close()
+	ts := authHeaderServer(t)
+
+	oauth2TokenSources.Lock()
+	before := len(oauth2TokenSources.m)
+	oauth2TokenSources.Unlock()
+
+	for _, secret := range []string{"first", "second"} {
+		cfg := &config.OAuth2Config{ClientID: "rotating", ClientSecret: secret, TokenURL: idp.URL}
+		transport, err := newOAuth2Transport(http.DefaultTransport, http.DefaultTransport, cfg, config.TLSConfig{}, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := getBody(t, &http.Client{Transport: transport}, ts.URL); got != "Bearer token-"+secret {
+			t.Fatalf("unexpected Authorization header %q", got)
+		}
+	}
+
+	oauth2TokenSources.Lock()
+	defer oauth2TokenSources.Unlock()
+	if n := len(oauth2TokenSources.m) - before; n != 1 {
+		t.Fatalf("expected the rotated secret to replace the cached token source, got %d new entries", n)
+	}
+}
+
+// countingTransport counts the requests sent through it.
+type countingTransport struct {
+	n atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.n.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestOAuth2TransportSlowTokenEndpoint(t *testing.T) {
+	release := make(chan struct{})
+	idp := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer idp.Close()
+	defer close(release)
+	ts := authHeaderServer(t)
+
+	base := &countingTransport{}
+	cfg := &config.OAuth2Config{ClientID: "exporter", ClientSecret: "slow", TokenURL: idp.URL}
+	transport, err := newOAuth2Transport(http.DefaultTransport, base, cfg, config.TLSConfig{}, 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	resp, err := (&http.Client{Transport: transport}).Get(ts.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected the token request to time out")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("token request took %s, expected it to time out", d)
+	}
+	if base.n.Load() == 0 {
+		t.Fatal("expected the token to be requested through the base transport")
+	}
+}
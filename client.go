@@ -0,0 +1,290 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/elasticsearch_exporter/config"
+	"github.com/prometheus-community/elasticsearch_exporter/pkg/roundtripper"
+)
+
+// clientConfig describes the HTTP client used to query one cluster, both in
+// single-target mode and for /probe.
+type clientConfig struct {
+	// tls settings are applied in order; later non-empty fields win.
+	tls []*config.TLSConfig
+	// authModules are applied in order, each wrapping the previous transport.
+	authModules []*config.AuthModule
+	// endpoints, if set, spreads requests over several equivalent endpoints.
+	endpoints         *roundtripper.EndpointPool
+	timeout           time.Duration
+	disableKeepAlives bool
+	metrics           *roundtripper.TransportMetrics
+}
+
+// newHTTPClient builds an HTTP client from c. It also returns the underlying
+// *http.Transport, so that callers can close its idle connections.
+func newHTTPClient(c clientConfig, logger *slog.Logger) (*http.Client, *http.Transport, error) {
+	var tlsSettings config.TLSConfig
+	for _, t := range c.tls {
+		if t == nil {
+			continue
+		}
+		if t.CAFile != "" {
+			tlsSettings.CAFile = t.CAFile
+		}
+		if t.CertFile != "" {
+			tlsSettings.CertFile = t.CertFile
+		}
+		if t.KeyFile != "" {
+			tlsSettings.KeyFile = t.KeyFile
+		}
+		if t.InsecureSkipVerify {
+			tlsSettings.InsecureSkipVerify = true
+		}
+	}
+
+	baseTransport := &http.Transport{
+		TLSClientConfig:   createTLSConfig(tlsSettings.CAFile, tlsSettings.CertFile, tlsSettings.KeyFile, tlsSettings.InsecureSkipVerify),
+		Proxy:             http.ProxyFromEnvironment,
+		ForceAttemptHTTP2: true,
+		DisableKeepAlives: c.disableKeepAlives,
+	}
+
+	var transport http.RoundTripper = baseTransport
+	for _, am := range c.authModules {
+		var err error
+		transport, err = authTransport(transport, baseTransport, am, tlsSettings, c.timeout, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// The endpoint is selected before the auth transports, as AWS signatures
+	// cover the host.
+	if c.endpoints != nil {
+		transport = roundtripper.NewFailoverTransport(transport, c.endpoints)
+	}
+	if c.metrics != nil {
+		transport = roundtripper.NewInstrumentedTransport(transport, c.metrics)
+	}
+
+	return &http.Client{
+		Timeout:   c.timeout,
+		Transport: transport,
+	}, baseTransport, nil
+}
+
+// swappableTransport forwards requests to a transport that can be replaced
+// while the client is in use, so that the single-target client picks up
+// changes of its auth module when the config file is reloaded.
+type swappableTransport struct {
+	mu   sync.RWMutex
+	t    http.RoundTripper
+	base *http.Transport
+}
+
+func (s *swappableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.RLock()
+	t := s.t
+	s.mu.RUnlock()
+	return t.RoundTrip(req)
+}
+
+// swap replaces the transport and closes the idle connections of the
+// previous one. Requests in flight complete on the previous transport.
+func (s *swappableTransport) swap(t http.RoundTripper, base *http.Transport) {
+	s.mu.Lock()
+	old := s.base
+	s.t, s.base = t, base
+	s.mu.Unlock()
+	if old != nil {
+		old.CloseIdleConnections()
+	}
+}
+
+// authModuleReloader returns a config reload hook that rebuilds the client
+// behind t from clientCfg when the named auth module changed and swaps it in
+// once the reload succeeds. The last TLS settings of clientCfg are replaced by
+// those of the auth module.
+func authModuleReloader(name string, current config.AuthModule, clientCfg clientConfig, t *swappableTransport, logger *slog.Logger) reloadHook {
+	return func(cfg *config.Config) (func(), error) {
+		am, ok := cfg.AuthModules[name]
+		if !ok {
+			return nil, fmt.Errorf("auth module %s not found in config file", name)
+		}
+		if reflect.DeepEqual(am, current) {
+			return nil, nil
+		}
+		c := clientCfg
+		c.tls = append(slices.Clone(clientCfg.tls[:len(clientCfg.tls)-1]), am.TLS)
+		c.authModules = []*config.AuthModule{&am}
+		client, base, err := newHTTPClient(c, logger)
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			if !maps.Equal(am.Options, current.Options) {
+				logger.Warn("changed auth module options only apply after a restart", "auth_module", name)
+			}
+			t.swap(client.Transport, base)
+			current = am
+			logger.Info("reloaded auth module", "auth_module", name)
+		}, nil
+	}
+}
+
+// authTransport wraps transport so that requests are authenticated as
+// configured by am. Requests to identity providers are sent through base, which
+// uses tlsSettings, with the given timeout.
+func authTransport(transport, base http.RoundTripper, am *config.AuthModule, tlsSettings config.TLSConfig, timeout time.Duration, logger *slog.Logger) (http.RoundTripper, error) {
+	switch strings.ToLower(am.Type) {
+	case "userpass":
+		return &transportWithBasicAuth{
+			underlyingTransport: transport,
+			username:            am.UserPass.Username,
+			password:            am.UserPass.ResolvePassword,
+		}, nil
+	case "apikey":
+		return &transportWithAPIKey{
+			underlyingTransport: transport,
+			apiKey:              am.ResolveAPIKey,
+		}, nil
+	case "bearer":
+		return &transportWithBearerToken{
+			underlyingTransport: transport,
+			token:               am.Bearer.ResolveToken,
+		}, nil
+	case "oauth2":
+		t, err := newOAuth2Transport(transport, base, am.OAuth2, tlsSettings, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OAuth2 transport: %w", err)
+		}
+		return t, nil
+	case "aws":
+		var region, roleARN string
+		if am.AWS != nil {
+			region = am.AWS.Region
+			roleARN = am.AWS.RoleARN
+		}
+		t, err := roundtripper.NewAWSSigningTransport(transport, region, roleARN, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AWS signing transport: %w", err)
+		}
+		return t, nil
+	case "tls":
+		// Client certificates in the TLS config handle authentication.
+		return transport, nil
+	default:
+		return nil, fmt.Errorf("unsupported auth_module type %s", am.Type)
+	}
+}
+
+// targetEndpointPools keeps the endpoint pools of named targets across probes,
+// so that failed endpoints are skipped until their retry interval has passed.
+var targetEndpointPools = struct {
+	sync.Mutex
+	m map[string]*roundtripper.EndpointPool
+}{m: make(map[string]*roundtripper.EndpointPool)}
+
+// targetEndpointPool returns the endpoint pool of the named target with the
+// given URLs, creating it on first use.
+func targetEndpointPool(name string, urls []string, strategy string, retryAfter time.Duration) (*roundtripper.EndpointPool, error) {
+	key := strings.Join(append([]string{name, strategy, retryAfter.String()}, urls...), "\x00")
+
+	targetEndpointPools.Lock()
+	defer targetEndpointPools.Unlock()
+	if pool, ok := targetEndpointPools.m[key]; ok {
+		return pool, nil
+	}
+
+	var endpoints []*url.URL
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, parsed)
+	}
+	pool, err := roundtripper.NewEndpointPool(endpoints, strategy, retryAfter)
+	if err != nil {
+		return nil, err
+	}
+	targetEndpointPools.m[key] = pool
+	return pool, nil
+}
+
+// applyAuthModuleOptions appends the options of am as query parameters to u.
+func applyAuthModuleOptions(u *url.URL, am *config.AuthModule) {
+	if am == nil || len(am.Options) == 0 {
+		return
+	}
+	q := u.Query()
+	for k, v := range am.Options {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+}
+
+// legacyAuthModules returns the auth modules equivalent to the ES_API_KEY,
+// ES_BEARER_TOKEN and ES_OAUTH2_* environment variables and the --aws.* flags
+// of single-target mode. They are validated like the auth modules of the
+// config file, named after their environment variables.
+func legacyAuthModules(awsRegion, awsRoleARN string) ([]*config.AuthModule, error) {
+	var modules []*config.AuthModule
+	var names []string
+	if apiKey := os.Getenv("ES_API_KEY"); apiKey != "" {
+		modules = append(modules, &config.AuthModule{Type: "apikey", APIKey: apiKey})
+		names = append(names, "ES_API_KEY")
+	}
+	if token := os.Getenv("ES_BEARER_TOKEN"); token != "" {
+		modules = append(modules, &config.AuthModule{Type: "bearer", Bearer: &config.BearerConfig{Token: token}})
+		names = append(names, "ES_BEARER_TOKEN")
+	}
+	if clientID := os.Getenv("ES_OAUTH2_CLIENT_ID"); clientID != "" {
+		var scopes []string
+		for _, scope := range strings.Split(os.Getenv("ES_OAUTH2_SCOPES"), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+		modules = append(modules, &config.AuthModule{Type: "oauth2", OAuth2: &config.OAuth2Config{
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("ES_OAUTH2_CLIENT_SECRET"),
+			TokenURL:     os.Getenv("ES_OAUTH2_TOKEN_URL"),
+			Scopes:       scopes,
+		}})
+		names = append(names, "ES_OAUTH2_*")
+	}
+	if awsRegion != "" {
+		modules = append(modules, &config.AuthModule{Type: "aws", AWS: &config.AWSConfig{Region: awsRegion, RoleARN: awsRoleARN}})
+		names = append(names, "--aws.*")
+	}
+	for i, am := range modules {
+		if err := am.Validate(names[i]); err != nil {
+			return nil, err
+		}
+	}
+	return modules, nil
+}
@@ -0,0 +1,178 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/promslog"
+
+	"github.com/prometheus-community/elasticsearch_exporter/config"
+)
+
+func TestNewHTTPClientAuthModules(t *testing.T) {
+	ts := authHeaderServer(t)
+
+	tests := []struct {
+		name string
+		am   *config.AuthModule
+		want string
+	}{
+		{
+			name: "userpass",
+			am:   &config.AuthModule{Type: "userpass", UserPass: &config.UserPassConfig{Username: "u", Password: "p"}},
+			want: "Basic dTpw",
+		},
+		{
+			name: "apikey",
+			am:   &config.AuthModule{Type: "apikey", APIKey: "a2V5"},
+			want: "ApiKey a2V5",
+		},
+		{
+			name: "bearer",
+			am:   &config.AuthModule{Type: "bearer", Bearer: &config.BearerConfig{Token: "t0k3n"}},
+			want: "Bearer t0k3n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc, _, err := newHTTPClient(clientConfig{authModules: []*config.AuthModule{tt.am}}, promslog.NewNopLogger())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := getBody(t, hc, ts.URL); got != tt.want {
+				t.Errorf("expected Authorization header %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	_, _, err := newHTTPClient(clientConfig{authModules: []*config.AuthModule{{Type: "foobar"}}}, promslog.NewNopLogger())
+	if err == nil {
+		t.Error("expected error for unsupported auth module type")
+	}
+}
+
+func TestNewHTTPClientTLS(t *testing.T) {
+	_, transport, err := newHTTPClient(clientConfig{
+		tls: []*config.TLSConfig{
+			{InsecureSkipVerify: false},
+			nil,
+			{InsecureSkipVerify: true},
+		},
+		disableKeepAlives: true,
+	}, promslog.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected later TLS settings to override earlier ones")
+	}
+	if !transport.DisableKeepAlives {
+		t.Error("expected keep-alives to be disabled")
+	}
+}
+
+func TestAuthModuleReloader(t *testing.T) {
+	ts := authHeaderServer(t)
+	logger := promslog.NewNopLogger()
+
+	current := config.AuthModule{Type: "apikey", APIKey: "b2xk"}
+	clientCfg := clientConfig{
+		tls:         []*config.TLSConfig{{}, current.TLS},
+		authModules: []*config.AuthModule{&current},
+	}
+	client, base, err := newHTTPClient(clientCfg, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &swappableTransport{}
+	transport.swap(client.Transport, base)
+	hc := &http.Client{Transport: transport}
+	reload := authModuleReloader("es", current, clientCfg, transport, logger)
+
+	if got := getBody(t, hc, ts.URL); got != "ApiKey b2xk" {
+		t.Fatalf("expected startup API key, got %q", got)
+	}
+
+	// a changed auth module is only used once the reload is applied
+	apply, err := reload(&config.Config{AuthModules: map[string]config.AuthModule{
+		"es": {Type: "bearer", Bearer: &config.BearerConfig{Token: "bmV3"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := getBody(t, hc, ts.URL); got != "ApiKey b2xk" {
+		t.Fatalf("expected startup API key before the reload is applied, got %q", got)
+	}
+	apply()
+	if got := getBody(t, hc, ts.URL); got != "Bearer bmV3" {
+		t.Errorf("expected reloaded bearer token, got %q", got)
+	}
+
+	// a config without the auth module fails the reload
+	if _, err := reload(&config.Config{}); err == nil {
+		t.Error("expected error for missing auth module")
+	}
+	if got := getBody(t, hc, ts.URL); got != "Bearer bmV3" {
+		t.Errorf("expected previous auth module to be kept, got %q", got)
+	}
+}
+
+func TestLegacyAuthModules(t *testing.T) {
+	t.Setenv("ES_API_KEY", "a2V5")
+	t.Setenv("ES_BEARER_TOKEN", "")
+	t.Setenv("ES_OAUTH2_CLIENT_ID", "")
+
+	modules, err := legacyAuthModules("us-east-1", "arn:aws:iam::123456789012:role/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 2 || modules[0].Type != "apikey" || modules[1].Type != "aws" {
+		t.Fatalf("expected apikey and aws modules, got %+v", modules)
+	}
+	if modules[1].AWS.RoleARN != "arn:aws:iam::123456789012:role/metrics" {
+		t.Errorf("expected role ARN to be kept, got %+v", modules[1].AWS)
+	}
+}
+
+func TestLegacyAuthModulesInvalidOAuth2(t *testing.T) {
+	t.Setenv("ES_API_KEY", "")
+	t.Setenv("ES_BEARER_TOKEN", "")
+	t.Setenv("ES_OAUTH2_CLIENT_ID", "exporter")
+	t.Setenv("ES_OAUTH2_CLIENT_SECRET", "s3cr3t")
+	t.Setenv("ES_OAUTH2_TOKEN_URL", "")
+
+	if _, err := legacyAuthModules("", ""); err == nil || !strings.Contains(err.Error(), "token_url") {
+		t.Fatalf("expected a missing token URL to be rejected, got %v", err)
+	}
+
+	t.Setenv("ES_OAUTH2_TOKEN_URL", "https://idp.example.com/token")
+	t.Setenv("ES_OAUTH2_CLIENT_SECRET", "")
+	if _, err := legacyAuthModules("", ""); err == nil || !strings.Contains(err.Error(), "client_secret") {
+		t.Fatalf("expected a missing client secret to be rejected, got %v", err)
+	}
+}
+
+func TestApplyAuthModuleOptions(t *testing.T) {
+	u, _ := url.Parse("http://localhost:9200/?a=b")
+	applyAuthModuleOptions(u, &config.AuthModule{Options: map[string]string{"sslmode": "disable"}})
+	if got := u.Query().Get("sslmode"); got != "disable" || u.Query().Get("a") != "b" {
+		t.Errorf("expected options to be appended, got %s", u.RawQuery)
+	}
+	applyAuthModuleOptions(u, nil)
+}
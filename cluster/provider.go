@@ -27,7 +27,14 @@ import (
 )
 
 type Info struct {
-	ClusterName string `json:"cluster_name"`
+	ClusterName string      `json:"cluster_name"`
+	Version     VersionInfo `json:"version"`
+}
+
+// VersionInfo is the version of Elasticsearch answering the cluster info
+// request.
+type VersionInfo struct {
+	Number string `json:"number"`
 }
 
 type InfoProvider struct {
@@ -107,7 +114,7 @@ func (i *InfoProvider) GetInfo(ctx context.Context) (Info, error) {
 		return Info{}, fmt.Errorf("failed to unmarshal cluster info: %w", err)
 	}
 
-	info = Info{ClusterName: infoResponse.ClusterName}
+	info = Info{ClusterName: infoResponse.ClusterName, Version: infoResponse.Version}
 	i.lastClusterInfo = info
 	i.lastError = nil
 	i.cachedAt = time.Now()
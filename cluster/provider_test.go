@@ -30,6 +30,7 @@ func TestInfoProvider_GetInfo(t *testing.T) {
 	timesURLCalled := 0
 	expectedInfo := Info{
 		ClusterName: "test-cluster-1",
+		Version:     VersionInfo{Number: "5.6.9"},
 	}
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
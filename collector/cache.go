@@ -0,0 +1,237 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	modeSync       = "sync"
+	modeBackground = "background"
+)
+
+var scrapeCacheAgeDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "scrape", "cache_age_seconds"),
+	"elasticsearch_exporter: Age of the cached metrics served for a collector.",
+	[]string{"collector"},
+	nil,
+)
+
+// collectorSchedule controls how often and how long a collector may query
+// Elasticsearch.
+type collectorSchedule struct {
+	timeout     time.Duration
+	minInterval time.Duration
+	mode        string
+}
+
+// cached reports whether the collector serves its metrics from a cache.
+func (s collectorSchedule) cached() bool {
+	return s.minInterval > 0
+}
+
+// collectorRunner executes a single collector according to its schedule. For
+// cached collectors it keeps the metrics of the last successful run, so that
+// scrapes within min_interval, or all scrapes in background mode, are served
+// without querying Elasticsearch. Failed or cancelled runs are not cached, so
+// that a single slow scrape does not replace good metrics for min_interval.
+type collectorRunner struct {
+	name     string
+	c        Collector
+	schedule collectorSchedule
+	logger   *slog.Logger
+
+	*runnerState
+}
+
+// runnerState is the cache and the error counters of a collector runner. It
+// outlives the runner if it comes from RunnerStates.
+type runnerState struct {
+	mu         sync.Mutex
+	metrics    []prometheus.Metric
+	updatedAt  time.Time
+	succeeded  bool
+	background bool
+
+	// errors counts failed runs by reason. It has its own lock as runs of
+	// cached collectors happen while mu is held.
+	errorsMu sync.Mutex
+	errors   map[string]float64
+}
+
+func newRunnerState() *runnerState {
+	return &runnerState{errors: make(map[string]float64)}
+}
+
+func newCollectorRunner(name string, c Collector, schedule collectorSchedule, logger *slog.Logger) *collectorRunner {
+	return &collectorRunner{
+		name:        name,
+		c:           c,
+		schedule:    schedule,
+		logger:      logger,
+		runnerState: newRunnerState(),
+	}
+}
+
+// RunnerStates keeps the cached metrics and error counters of collectors
+// across ElasticsearchCollectors, e.g. those of the probes of one target, so
+// that min_interval applies to all of them. See WithRunnerStates.
+type RunnerStates struct {
+	mu     sync.Mutex
+	states map[string]*runnerState
+}
+
+func NewRunnerStates() *RunnerStates {
+	return &RunnerStates{states: make(map[string]*runnerState)}
+}
+
+// get returns the state of the named collector, creating it on first use.
+func (s *RunnerStates) get(name string) *runnerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.states[name]
+	if !ok {
+		st = newRunnerState()
+		s.states[name] = st
+	}
+	return st
+}
+
+// collect writes the collector's metrics to ch, querying Elasticsearch only
+// if no sufficiently fresh cached result is available.
+func (r *collectorRunner) collect(ctx context.Context, ch chan<- prometheus.Metric, uc UpdateContext) {
+	defer r.collectErrors(ch)
+
+	if !r.schedule.cached() {
+		r.execute(ctx, ch, uc)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.background && (r.updatedAt.IsZero() || time.Since(r.updatedAt) >= r.schedule.minInterval) {
+		metrics, err := r.gather(ctx, uc)
+		switch {
+		case cacheable(ctx, err):
+			r.store(metrics, true)
+		case r.updatedAt.IsZero():
+			// Nothing to fall back to, so the failed run is served but
+			// not cached, and the next scrape runs the collector again.
+			for _, m := range metrics {
+				ch <- m
+			}
+			return
+		}
+	}
+	if r.updatedAt.IsZero() {
+		// Background collector that has not completed its first run yet.
+		return
+	}
+
+	for _, m := range r.metrics {
+		ch <- m
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeCacheAgeDesc, prometheus.GaugeValue, time.Since(r.updatedAt).Seconds(), r.name)
+}
+
+// run refreshes the cached metrics every min_interval until ctx is done.
+func (r *collectorRunner) run(ctx context.Context, uc UpdateContext) {
+	r.mu.Lock()
+	r.background = true
+	r.mu.Unlock()
+
+	ticker := time.NewTicker(r.schedule.minInterval)
+	defer ticker.Stop()
+	for {
+		metrics, err := r.gather(ctx, uc)
+
+		r.mu.Lock()
+		// A failed run only replaces the metrics of another failed run,
+		// so that scrapes report the failure until a run succeeds.
+		if ok := cacheable(ctx, err); ok || !r.succeeded {
+			r.store(metrics, ok)
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// store replaces the cached metrics. r.mu must be held.
+func (r *collectorRunner) store(metrics []prometheus.Metric, succeeded bool) {
+	r.metrics = metrics
+	r.updatedAt = time.Now()
+	r.succeeded = succeeded
+}
+
+// cacheable reports whether the result of a run that returned err within ctx
+// may be cached. Runs that failed or whose scrape was cancelled are not.
+func cacheable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && (err == nil || IsNoDataError(err))
+}
+
+// gather executes the collector and returns all metrics it produced.
+func (r *collectorRunner) gather(ctx context.Context, uc UpdateContext) ([]prometheus.Metric, error) {
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	var metrics []prometheus.Metric
+	go func() {
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+		close(done)
+	}()
+
+	err := r.execute(ctx, ch, uc)
+	close(ch)
+	<-done
+
+	return metrics, err
+}
+
+// execute runs the collector once, bounded by its timeout if one is set.
+func (r *collectorRunner) execute(ctx context.Context, ch chan<- prometheus.Metric, uc UpdateContext) error {
+	if r.schedule.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.schedule.timeout)
+		defer cancel()
+	}
+	err := execute(ctx, r.name, r.c, ch, r.logger, uc)
+	if err != nil {
+		r.errorsMu.Lock()
+		r.errors[errorReason(err)]++
+		r.errorsMu.Unlock()
+	}
+	return err
+}
+
+// collectErrors writes the error counters of the collector to ch.
+func (r *collectorRunner) collectErrors(ch chan<- prometheus.Metric) {
+	r.errorsMu.Lock()
+	defer r.errorsMu.Unlock()
+	for reason, count := range r.errors {
+		ch <- prometheus.MustNewConstMetric(scrapeErrorsDesc, prometheus.CounterValue, count, r.name, reason)
+	}
+}
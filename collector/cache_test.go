@@ -0,0 +1,205 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/promslog"
+)
+
+var countingDesc = prometheus.NewDesc("test_updates", "Number of updates.", nil, nil)
+
+// countingCollector counts its updates and emits the count as a metric.
+type countingCollector struct {
+	updates atomic.Int64
+}
+
+func (c *countingCollector) Update(ctx context.Context, _ UpdateContext, ch chan<- prometheus.Metric) error {
+	n := c.updates.Add(1)
+	ch <- prometheus.MustNewConstMetric(countingDesc, prometheus.GaugeValue, float64(n))
+	return ctx.Err()
+}
+
+// collectRunner collects r once and returns the names of the emitted metrics.
+func collectRunner(r *collectorRunner) []string {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		r.collect(context.Background(), ch, &mockUpdateContext{})
+		close(ch)
+	}()
+
+	var names []string
+	for m := range ch {
+		names = append(names, m.Desc().String())
+	}
+	return names
+}
+
+func TestCollectorRunnerMinInterval(t *testing.T) {
+	c := &countingCollector{}
+	r := newCollectorRunner("counting", c, collectorSchedule{minInterval: time.Hour, mode: modeSync}, promslog.NewNopLogger())
+
+	first := collectRunner(r)
+	second := collectRunner(r)
+
+	if got := c.updates.Load(); got != 1 {
+		t.Fatalf("expected 1 update within min interval, got %d", got)
+	}
+	if len(first) != len(second) {
+		t.Fatalf("expected cached scrape to return the same metrics, got %d and %d", len(first), len(second))
+	}
+	// counting metric, scrape duration, scrape success and cache age
+	if len(second) != 4 {
+		t.Fatalf("expected 4 metrics, got %d: %v", len(second), second)
+	}
+}
+
+func TestCollectorRunnerWithoutMinInterval(t *testing.T) {
+	c := &countingCollector{}
+	r := newCollectorRunner("counting", c, collectorSchedule{mode: modeSync}, promslog.NewNopLogger())
+
+	collectRunner(r)
+	names := collectRunner(r)
+
+	if got := c.updates.Load(); got != 2 {
+		t.Fatalf("expected 2 updates, got %d", got)
+	}
+	// counting metric, scrape duration and scrape success
+	if len(names) != 3 {
+		t.Fatalf("expected 3 metrics, got %d: %v", len(names), names)
+	}
+}
+
+func TestCollectorRunnerBackground(t *testing.T) {
+	c := &countingCollector{}
+	r := newCollectorRunner("counting", c, collectorSchedule{minInterval: time.Hour, mode: modeBackground}, promslog.NewNopLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.run(ctx, &mockUpdateContext{})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.updates.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("background collector did not run")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for range 3 {
+		collectRunner(r)
+	}
+	if got := c.updates.Load(); got != 1 {
+		t.Fatalf("expected scrapes to be served from the background cache, got %d updates", got)
+	}
+}
+
+// collectRunnerWithContext collects r once with ctx and returns the names of
+// the emitted metrics.
+func collectRunnerWithContext(ctx context.Context, r *collectorRunner) []string {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		r.collect(ctx, ch, &mockUpdateContext{})
+		close(ch)
+	}()
+
+	var names []string
+	for m := range ch {
+		names = append(names, m.Desc().String())
+	}
+	return names
+}
+
+func TestCollectorRunnerFailedRunsNotCached(t *testing.T) {
+	c := &countingCollector{}
+	r := newCollectorRunner("counting", c, collectorSchedule{minInterval: 20 * time.Millisecond, mode: modeSync}, promslog.NewNopLogger())
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Without a cached result, the failed run is served but not cached.
+	// counting metric, scrape duration, scrape success and errors
+	if names := collectRunnerWithContext(cancelled, r); len(names) != 4 {
+		t.Fatalf("expected the metrics of the failed run without cache age, got %v", names)
+	}
+	if !r.updatedAt.IsZero() {
+		t.Fatal("expected the failed run not to be cached")
+	}
+	collectRunner(r)
+	if got := c.updates.Load(); got != 2 || !r.succeeded {
+		t.Fatalf("expected the collector to run again after a failed run, got %d updates", got)
+	}
+	updatedAt := r.updatedAt
+
+	// A cancelled scrape after min_interval serves the previous result and
+	// does not replace it.
+	time.Sleep(30 * time.Millisecond)
+	// cached metrics, cache age and errors
+	if names := collectRunnerWithContext(cancelled, r); len(names) != 5 {
+		t.Fatalf("expected the cached metrics with their age, got %v", names)
+	}
+	if got := c.updates.Load(); got != 3 || !r.updatedAt.Equal(updatedAt) || !r.succeeded {
+		t.Fatalf("expected the previous result to be kept after %d updates", got)
+	}
+	collectRunner(r)
+	if got := c.updates.Load(); got != 4 || r.updatedAt.Equal(updatedAt) {
+		t.Fatalf("expected the collector to run again after the cancelled scrape, got %d updates", got)
+	}
+}
+
+func TestRunnerStatesShared(t *testing.T) {
+	states := NewRunnerStates()
+	schedule := collectorSchedule{minInterval: time.Hour, mode: modeSync}
+
+	// Runners of consecutive probes share the cache of their collector.
+	first, second := &countingCollector{}, &countingCollector{}
+	r1 := newCollectorRunner("counting", first, schedule, promslog.NewNopLogger())
+	r1.runnerState = states.get("counting")
+	r2 := newCollectorRunner("counting", second, schedule, promslog.NewNopLogger())
+	r2.runnerState = states.get("counting")
+
+	collectRunner(r1)
+	if names := collectRunner(r2); len(names) != 4 {
+		t.Fatalf("expected the cached metrics of the first runner, got %v", names)
+	}
+	if first.updates.Load() != 1 || second.updates.Load() != 0 {
+		t.Fatalf("expected a single update, got %d and %d", first.updates.Load(), second.updates.Load())
+	}
+	if states.get("other") == states.get("counting") {
+		t.Error("expected collectors to have their own state")
+	}
+}
+
+func TestCollectorRunnerTimeout(t *testing.T) {
+	r := newCollectorRunner("blocking", blockingCollector{}, collectorSchedule{timeout: 50 * time.Millisecond, mode: modeSync}, promslog.NewNopLogger())
+
+	begin := time.Now()
+	collectRunner(r)
+	if d := time.Since(begin); d > 2*time.Second {
+		t.Fatalf("expected collector to be cancelled after its timeout, took %s", d)
+	}
+}
+
+// blockingCollector blocks until its context is done.
+type blockingCollector struct{}
+
+func (blockingCollector) Update(ctx context.Context, _ UpdateContext, _ chan<- prometheus.Metric) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
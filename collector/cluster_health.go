@@ -14,9 +14,8 @@
 package collector
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -43,6 +42,10 @@ type clusterHealthStatusMetric struct {
 	Labels func(clusterName, color string) []string
 }
 
+func init() {
+	registerCollector("cluster-health", defaultEnabled, NewClusterHealth)
+}
+
 // ClusterHealth type defines the collector struct
 type ClusterHealth struct {
 	logger *slog.Logger
@@ -54,7 +57,7 @@ type ClusterHealth struct {
 }
 
 // NewClusterHealth returns a new Collector exposing ClusterHealth stats.
-func NewClusterHealth(logger *slog.Logger, client *http.Client, url *url.URL) *ClusterHealth {
+func NewClusterHealth(logger *slog.Logger, url *url.URL, client *http.Client) (Collector, error) {
 	subsystem := "cluster_health"
 
 	return &ClusterHealth{
@@ -199,63 +202,26 @@ func NewClusterHealth(logger *slog.Logger, client *http.Client, url *url.URL) *C
 				return 0
 			},
 		},
-	}
-}
-
-// Describe set Prometheus metrics descriptions.
-func (c *ClusterHealth) Describe(ch chan<- *prometheus.Desc) {
-	for _, metric := range c.metrics {
-		ch <- metric.Desc
-	}
-	ch <- c.statusMetric.Desc
+	}, nil
 }
 
-func (c *ClusterHealth) fetchAndDecodeClusterHealth() (clusterHealthResponse, error) {
+func (c *ClusterHealth) fetchAndDecodeClusterHealth(ctx context.Context) (clusterHealthResponse, error) {
 	var chr clusterHealthResponse
 
 	u := *c.url
 	u.Path = path.Join(u.Path, "/_cluster/health")
-	res, err := c.client.Get(u.String())
-	if err != nil {
-		return chr, fmt.Errorf("failed to get cluster health from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
-	}
-
-	defer func() {
-		err = res.Body.Close()
-		if err != nil {
-			c.logger.Warn(
-				"failed to close http.Client",
-				"err", err,
-			)
-		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return chr, fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
-	}
-
-	bts, err := io.ReadAll(res.Body)
-	if err != nil {
-		return chr, err
-	}
-
-	if err := json.Unmarshal(bts, &chr); err != nil {
+	if err := getAndDecodeURL(ctx, c.client, c.logger, u.String(), &chr); err != nil {
 		return chr, err
 	}
 
 	return chr, nil
 }
 
-// Collect collects ClusterHealth metrics.
-func (c *ClusterHealth) Collect(ch chan<- prometheus.Metric) {
-	clusterHealthResp, err := c.fetchAndDecodeClusterHealth()
+// Update collects ClusterHealth metrics.
+func (c *ClusterHealth) Update(ctx context.Context, _ UpdateContext, ch chan<- prometheus.Metric) error {
+	clusterHealthResp, err := c.fetchAndDecodeClusterHealth(ctx)
 	if err != nil {
-		c.logger.Warn(
-			"failed to fetch and decode cluster health",
-			"err", err,
-		)
-		return
+		return fmt.Errorf("failed to fetch and decode cluster health: %w", err)
 	}
 
 	for _, metric := range c.metrics {
@@ -275,4 +241,6 @@ func (c *ClusterHealth) Collect(ch chan<- prometheus.Metric) {
 			clusterHealthResp.ClusterName, color,
 		)
 	}
+
+	return nil
 }
@@ -215,9 +215,12 @@ func TestClusterHealth(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			c := NewClusterHealth(promslog.NewNopLogger(), http.DefaultClient, u)
+			c, err := NewClusterHealth(promslog.NewNopLogger(), u, http.DefaultClient)
+			if err != nil {
+				t.Fatal(err)
+			}
 
-			if err := testutil.CollectAndCompare(c, strings.NewReader(tt.want)); err != nil {
+			if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(tt.want)); err != nil {
 				t.Fatal(err)
 			}
 		})
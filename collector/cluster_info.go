@@ -15,8 +15,6 @@ package collector
 
 import (
 	"context"
-	"encoding/json"
-	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -77,25 +75,10 @@ type VersionInfo struct {
 	LuceneVersion semver.Version `json:"lucene_version"`
 }
 
-func (c *ClusterInfoCollector) Update(_ context.Context, uc UpdateContext, ch chan<- prometheus.Metric) error {
+func (c *ClusterInfoCollector) Update(ctx context.Context, uc UpdateContext, ch chan<- prometheus.Metric) error {
 	var info ClusterInfoResponse
 
-	resp, err := c.hc.Get(c.u.String())
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			c.logger.Warn("failed to close response body", "err", cerr)
-		}
-	}()
-
-	bts, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
-	if err := json.Unmarshal(bts, &info); err != nil {
+	if err := getAndDecodeURL(ctx, c.hc, c.logger, c.u.String(), &info); err != nil {
 		return err
 	}
 
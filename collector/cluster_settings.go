@@ -15,9 +15,7 @@ package collector
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -151,35 +149,15 @@ func (c *ClusterSettingsCollector) Update(ctx context.Context, uc UpdateContext,
 	q.Set("include_defaults", "true")
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return err
-	}
-
-	resp, err := c.hc.Do(req)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			c.logger.Warn("failed to close response body", "err", cerr)
-		}
-	}()
-
-	bts, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
 	var data clusterSettingsResponse
-	if err := json.Unmarshal(bts, &data); err != nil {
+	if err := getAndDecodeURL(ctx, c.hc, c.logger, u.String(), &data); err != nil {
 		return err
 	}
 
 	// Merge all settings into one struct
 	merged := data.Defaults
 
-	err = mergo.Merge(&merged, data.Persistent, mergo.WithOverride)
+	err := mergo.Merge(&merged, data.Persistent, mergo.WithOverride)
 	if err != nil {
 		return err
 	}
@@ -28,6 +28,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/prometheus-community/elasticsearch_exporter/cluster"
+	"github.com/prometheus-community/elasticsearch_exporter/config"
 )
 
 const (
@@ -41,9 +42,10 @@ const (
 type factoryFunc func(logger *slog.Logger, u *url.URL, hc *http.Client) (Collector, error)
 
 var (
-	factories        = make(map[string]factoryFunc)
-	collectorState   = make(map[string]*bool)
-	forcedCollectors = map[string]bool{} // collectors which have been explicitly enabled or disabled
+	factories          = make(map[string]factoryFunc)
+	collectorState     = make(map[string]*bool)
+	collectorSchedules = make(map[string]*collectorSchedule)
+	forcedCollectors   = map[string]bool{} // collectors which have been explicitly enabled or disabled
 )
 
 var (
@@ -83,6 +85,19 @@ func registerCollector(name string, isDefaultEnabled bool, createFunc factoryFun
 	flag := kingpin.Flag(flagName, flagHelp).Default(defaultValue).Action(collectorFlagAction(name)).Bool()
 	collectorState[name] = flag
 
+	// Create scheduling flags for this collector
+	schedule := &collectorSchedule{}
+	kingpin.Flag(flagName+".timeout",
+		fmt.Sprintf("Timeout for a single run of the %s collector (0 means no collector-specific timeout).", name)).
+		Default("0s").DurationVar(&schedule.timeout)
+	kingpin.Flag(flagName+".min-interval",
+		fmt.Sprintf("Minimum interval between two runs of the %s collector; scrapes in between are served from cache.", name)).
+		Default("0s").DurationVar(&schedule.minInterval)
+	kingpin.Flag(flagName+".mode",
+		fmt.Sprintf("Run mode of the %s collector: sync runs on scrape, background refreshes every min-interval.", name)).
+		Default(modeSync).EnumVar(&schedule.mode, modeSync, modeBackground)
+	collectorSchedules[name] = schedule
+
 	// Register the create function for this collector
 	factories[name] = createFunc
 }
@@ -93,6 +108,25 @@ type ElasticsearchCollector struct {
 	esURL      *url.URL
 	httpClient *http.Client
 	cluserInfo *cluster.InfoProvider
+	ctx        context.Context
+	configs    map[string]config.CollectorConfig
+	runners    map[string]*collectorRunner
+	enabled    []string
+	target     targetSettings
+	states     *RunnerStates
+}
+
+// targetSettings overrides collector settings that are otherwise taken from
+// command line flags, so that each probe target can be scraped differently.
+type targetSettings struct {
+	nodes      []string
+	shardLevel *bool
+}
+
+// targetConfigurer is implemented by collectors that support per-target
+// settings.
+type targetConfigurer interface {
+	configure(s targetSettings)
 }
 
 type Option func(*ElasticsearchCollector) error
@@ -111,34 +145,77 @@ func NewElasticsearchCollector(logger *slog.Logger, filters []string, options ..
 		return nil, fmt.Errorf("cluster info provider is not set")
 	}
 
-	f := make(map[string]bool)
-	for _, filter := range filters {
-		enabled, exist := collectorState[filter]
-		if !exist {
-			return nil, fmt.Errorf("missing collector: %s", filter)
+	for name := range e.configs {
+		if _, exist := factories[name]; !exist {
+			return nil, fmt.Errorf("unknown collector in config: %s", name)
 		}
-		if !*enabled {
-			return nil, fmt.Errorf("disabled collector: %s", filter)
+	}
+
+	enabled := make(map[string]bool)
+	if e.enabled != nil {
+		if err := CheckCollectors(e.enabled); err != nil {
+			return nil, err
 		}
-		f[filter] = true
+		for _, name := range e.enabled {
+			enabled[name] = true
+		}
+	} else {
+		for name, state := range collectorState {
+			enabled[name] = *state
+		}
+	}
+
+	f, err := parseFilters(filters, enabled)
+	if err != nil {
+		return nil, err
 	}
 	collectors := make(map[string]Collector)
-	for key, enabled := range collectorState {
-		if !*enabled || (len(f) > 0 && !f[key]) {
+	runners := make(map[string]*collectorRunner)
+	for key := range factories {
+		if !enabled[key] || (len(f) > 0 && !f[key]) {
 			continue
 		}
+		schedule, err := e.schedule(key)
+		if err != nil {
+			return nil, err
+		}
 		collector, err := factories[key](logger.With("collector", key), e.esURL, e.httpClient)
 		if err != nil {
 			return nil, err
 		}
+		if c, ok := collector.(targetConfigurer); ok {
+			c.configure(e.target)
+		}
 		collectors[key] = collector
+		runner := newCollectorRunner(key, collector, schedule, logger)
+		if e.states != nil {
+			runner.runnerState = e.states.get(key)
+		}
+		runners[key] = runner
 	}
 
 	e.Collectors = collectors
+	e.runners = runners
 
 	return e, nil
 }
 
+// parseFilters validates that every filter names an enabled collector and
+// returns the filters as a set.
+func parseFilters(filters []string, enabled map[string]bool) (map[string]bool, error) {
+	f := make(map[string]bool)
+	for _, filter := range filters {
+		if _, exist := factories[filter]; !exist {
+			return nil, fmt.Errorf("missing collector: %s", filter)
+		}
+		if !enabled[filter] {
+			return nil, fmt.Errorf("disabled collector: %s", filter)
+		}
+		f[filter] = true
+	}
+	return f, nil
+}
+
 func WithElasticsearchURL(esURL *url.URL) Option {
 	return func(e *ElasticsearchCollector) error {
 		e.esURL = esURL
@@ -160,28 +237,170 @@ func WithClusterInfoProvider(cl *cluster.InfoProvider) Option {
 	}
 }
 
+// WithCollectorConfigs overrides the flag-based timeout, min interval and mode
+// of individual collectors.
+func WithCollectorConfigs(configs map[string]config.CollectorConfig) Option {
+	return func(e *ElasticsearchCollector) error {
+		e.configs = configs
+		return nil
+	}
+}
+
+// WithCollectors enables exactly the given collectors, regardless of their
+// --collector.* flags.
+func WithCollectors(names []string) Option {
+	return func(e *ElasticsearchCollector) error {
+		e.enabled = names
+		return nil
+	}
+}
+
+// WithShardLevel overrides --es.shards for the indices collector.
+func WithShardLevel(enabled bool) Option {
+	return func(e *ElasticsearchCollector) error {
+		e.target.shardLevel = &enabled
+		return nil
+	}
+}
+
+// CheckCollectors returns an error if any of the names is not a known
+// collector.
+func CheckCollectors(names []string) error {
+	for _, name := range names {
+		if _, exist := factories[name]; !exist {
+			return fmt.Errorf("missing collector: %s", name)
+		}
+	}
+	return nil
+}
+
+// WithRunnerStates makes the collectors share their cached metrics and error
+// counters with other ElasticsearchCollectors created with the same states.
+// Their collectors run synchronously, also in background mode.
+func WithRunnerStates(states *RunnerStates) Option {
+	return func(e *ElasticsearchCollector) error {
+		e.states = states
+		return nil
+	}
+}
+
+// WithNodeSelector overrides --es.node, --es.all and --es.sniff for the nodes
+// collector with a list of node filter expressions.
+func WithNodeSelector(exprs []string) Option {
+	return func(e *ElasticsearchCollector) error {
+		nodes, err := config.ParseNodeSelector(exprs)
+		if err != nil {
+			return err
+		}
+		e.target.nodes = nodes
+		return nil
+	}
+}
+
+// schedule returns the schedule of the named collector, merging its config
+// file settings over the command line flags.
+func (e *ElasticsearchCollector) schedule(name string) (collectorSchedule, error) {
+	var s collectorSchedule
+	if flags, ok := collectorSchedules[name]; ok {
+		s = *flags
+	}
+	if s.mode == "" {
+		s.mode = modeSync
+	}
+
+	if cfg, ok := e.configs[name]; ok {
+		if cfg.Timeout > 0 {
+			s.timeout = cfg.Timeout
+		}
+		if cfg.MinInterval > 0 {
+			s.minInterval = cfg.MinInterval
+		}
+		if cfg.Mode != "" {
+			s.mode = cfg.Mode
+		}
+	}
+
+	if s.mode == modeBackground && s.minInterval <= 0 {
+		return s, fmt.Errorf("collector %s: background mode requires a min interval", name)
+	}
+	return s, nil
+}
+
+// Run starts refreshing the collectors configured for background mode. It
+// returns immediately; the refresh loops stop once ctx is done. Collectors in
+// background mode that are never run behave like sync collectors with a min
+// interval.
+func (e *ElasticsearchCollector) Run(ctx context.Context) {
+	uc := NewDefaultUpdateContext(e.cluserInfo)
+	for _, r := range e.runners {
+		if r.schedule.mode == modeBackground {
+			go r.run(ctx, uc)
+		}
+	}
+}
+
+// WithContext returns a copy of e whose scrapes are bound to ctx. Requests to
+// Elasticsearch are cancelled once ctx is done, e.g. when the scrape times out.
+func (e ElasticsearchCollector) WithContext(ctx context.Context) *ElasticsearchCollector {
+	e.ctx = ctx
+	return &e
+}
+
+// Filter returns a copy of e that only runs the given collectors. The copy
+// shares the collector instances, and thereby their caches and background
+// refreshes, with e.
+func (e ElasticsearchCollector) Filter(filters []string) (*ElasticsearchCollector, error) {
+	enabled := make(map[string]bool)
+	for name := range e.Collectors {
+		enabled[name] = true
+	}
+	f, err := parseFilters(filters, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	collectors := make(map[string]Collector)
+	runners := make(map[string]*collectorRunner)
+	for name := range f {
+		if c, ok := e.Collectors[name]; ok {
+			collectors[name] = c
+			runners[name] = e.runners[name]
+		}
+	}
+	e.Collectors = collectors
+	e.runners = runners
+	return &e, nil
+}
+
 // Describe implements the prometheus.Collector interface.
 func (e ElasticsearchCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- scrapeDurationDesc
 	ch <- scrapeSuccessDesc
+	ch <- scrapeCacheAgeDesc
+	ch <- scrapeErrorsDesc
 }
 
 // Collect implements the prometheus.Collector interface.
 func (e ElasticsearchCollector) Collect(ch chan<- prometheus.Metric) {
 	uc := NewDefaultUpdateContext(e.cluserInfo)
 	wg := sync.WaitGroup{}
-	ctx := context.TODO()
-	wg.Add(len(e.Collectors))
-	for name, c := range e.Collectors {
-		go func(name string, c Collector) {
-			execute(ctx, name, c, ch, e.logger, uc)
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	wg.Add(len(e.runners))
+	for _, r := range e.runners {
+		go func(r *collectorRunner) {
+			r.collect(ctx, ch, uc)
 			wg.Done()
-		}(name, c)
+		}(r)
 	}
 	wg.Wait()
 }
 
-func execute(ctx context.Context, name string, c Collector, ch chan<- prometheus.Metric, logger *slog.Logger, uc UpdateContext) {
+// execute runs the collector once, emits its scrape duration and success and
+// returns the error of the collector, if any.
+func execute(ctx context.Context, name string, c Collector, ch chan<- prometheus.Metric, logger *slog.Logger, uc UpdateContext) error {
 	begin := time.Now()
 	err := c.Update(ctx, uc, ch)
 	duration := time.Since(begin)
@@ -191,7 +410,7 @@ func execute(ctx context.Context, name string, c Collector, ch chan<- prometheus
 		if IsNoDataError(err) {
 			logger.Debug("collector returned no data", "name", name, "duration_seconds", duration.Seconds(), "err", err)
 		} else {
-			logger.Warn("collector failed", "name", name, "duration_seconds", duration.Seconds(), "err", err)
+			logger.Warn("collector failed", "name", name, "duration_seconds", duration.Seconds(), "reason", errorReason(err), "err", err)
 		}
 		success = 0
 	} else {
@@ -200,6 +419,7 @@ func execute(ctx context.Context, name string, c Collector, ch chan<- prometheus
 	}
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, duration.Seconds(), name)
 	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success, name)
+	return err
 }
 
 // collectorFlagAction generates a new action function for the given collector
@@ -214,9 +434,24 @@ func collectorFlagAction(collector string) func(ctx *kingpin.ParseContext) error
 	}
 }
 
+// legacyFlagAction generates an action function for a deprecated es.* flag
+// that enables the given collectors when the flag is set to true, so that
+// existing command lines keep working after the move to --collector.* flags.
+func legacyFlagAction(enabled *bool, collectors ...string) func(ctx *kingpin.ParseContext) error {
+	return func(ctx *kingpin.ParseContext) error {
+		if !*enabled {
+			return nil
+		}
+		for _, c := range collectors {
+			*collectorState[c] = true
+		}
+		return nil
+	}
+}
+
 // ErrNoData indicates the collector found no data to collect, but had no other error.
 var ErrNoData = errors.New("collector returned no data")
 
 func IsNoDataError(err error) bool {
-	return err == ErrNoData
+	return errors.Is(err, ErrNoData)
 }
@@ -15,8 +15,17 @@ package collector
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
 
 	"github.com/prometheus-community/elasticsearch_exporter/cluster"
 )
@@ -37,8 +46,165 @@ func (w wrapCollector) Collect(ch chan<- prometheus.Metric) {
 	w.c.Update(context.Background(), &mockUpdateContext{}, ch)
 }
 
-type mockUpdateContext struct{}
+// wrapCollectorWithContext is like wrapCollector but passes uc to Update.
+type wrapCollectorWithContext struct {
+	c  Collector
+	uc UpdateContext
+}
+
+func (w wrapCollectorWithContext) Describe(_ chan<- *prometheus.Desc) {
+}
+
+func (w wrapCollectorWithContext) Collect(ch chan<- prometheus.Metric) {
+	w.c.Update(context.Background(), w.uc, ch)
+}
+
+type mockUpdateContext struct {
+	info cluster.Info
+}
 
 func (m *mockUpdateContext) GetClusterInfo(_ context.Context) (cluster.Info, error) {
-	return cluster.Info{}, nil
+	return m.info, nil
+}
+
+// TestElasticsearchCollectorWithContext verifies that a collector bound to a
+// context via WithContext aborts in-flight Elasticsearch requests once the
+// context deadline passes and reports the collector as failed.
+func TestElasticsearchCollectorWithContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	cleanup := setupClusterInfoState(t)
+	defer cleanup()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := promslog.NewNopLogger()
+	exp, err := NewElasticsearchCollector(logger, []string{},
+		WithElasticsearchURL(u),
+		WithHTTPClient(http.DefaultClient),
+		WithClusterInfoProvider(cluster.NewInfoProvider(logger, http.DefaultClient, u, time.Minute)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	want := `# HELP elasticsearch_scrape_success elasticsearch_exporter: Whether a collector succeeded.
+# TYPE elasticsearch_scrape_success gauge
+elasticsearch_scrape_success{collector="cluster-info"} 0
+`
+	begin := time.Now()
+	if err := testutil.CollectAndCompare(exp.WithContext(ctx), strings.NewReader(want), "elasticsearch_scrape_success"); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(begin); d > 2*time.Second {
+		t.Fatalf("expected scrape to be cancelled at the context deadline, took %s", d)
+	}
+}
+
+// TestElasticsearchCollectorFilter verifies that Filter restricts a collector
+// to the requested subset and rejects unknown or disabled collectors.
+func TestElasticsearchCollectorFilter(t *testing.T) {
+	enabled, disabled := true, false
+	for name, state := range map[string]*bool{"cluster-info": &enabled, "tasks": &enabled, "ilm": &disabled} {
+		original := collectorState[name]
+		collectorState[name] = state
+		defer func(name string) { collectorState[name] = original }(name)
+	}
+
+	u, err := url.Parse("http://localhost:9200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := promslog.NewNopLogger()
+	exp, err := NewElasticsearchCollector(logger, []string{},
+		WithElasticsearchURL(u),
+		WithHTTPClient(http.DefaultClient),
+		WithClusterInfoProvider(cluster.NewInfoProvider(logger, http.DefaultClient, u, time.Minute)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filtered, err := exp.Filter([]string{"tasks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered.Collectors) != 1 || filtered.Collectors["tasks"] != exp.Collectors["tasks"] {
+		t.Errorf("expected only the shared tasks collector, got %v", filtered.Collectors)
+	}
+	if len(exp.Collectors) != 2 {
+		t.Errorf("expected the original collector to be unchanged, got %v", exp.Collectors)
+	}
+
+	if _, err := exp.Filter([]string{"does-not-exist"}); err == nil {
+		t.Error("expected error for unknown collector")
+	}
+	if _, err := exp.Filter([]string{"ilm"}); err == nil {
+		t.Error("expected error for disabled collector")
+	}
+}
+
+func TestElasticsearchCollectorWithCollectors(t *testing.T) {
+	u, err := url.Parse("http://localhost:9200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := promslog.NewNopLogger()
+	infoProvider := cluster.NewInfoProvider(logger, http.DefaultClient, u, time.Minute)
+
+	exp, err := NewElasticsearchCollector(logger, []string{},
+		WithElasticsearchURL(u),
+		WithHTTPClient(http.DefaultClient),
+		WithClusterInfoProvider(infoProvider),
+		WithCollectors([]string{"indices", "shards", "nodes"}),
+		WithShardLevel(true),
+		WithNodeSelector([]string{"master:true,data_hot:true"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exp.Collectors) != 3 {
+		t.Fatalf("expected exactly the configured collectors, got %v", exp.Collectors)
+	}
+	if indices := exp.Collectors["indices"].(*Indices); !indices.shards {
+		t.Error("expected shard-level indices stats for the target")
+	}
+	if nodes := exp.Collectors["nodes"].(*Nodes); nodes.all || !slices.Equal(nodes.nodes, []string{"master:true", "data_hot:true"}) {
+		t.Errorf("expected node selector master:true,data_hot:true, got all=%t nodes=%q", nodes.all, nodes.nodes)
+	}
+
+	if _, err := exp.Filter([]string{"tasks"}); err == nil {
+		t.Error("expected error for collector not configured for the target")
+	}
+
+	_, err = NewElasticsearchCollector(logger, []string{},
+		WithElasticsearchURL(u),
+		WithHTTPClient(http.DefaultClient),
+		WithClusterInfoProvider(infoProvider),
+		WithCollectors([]string{"does-not-exist"}),
+	)
+	if err == nil {
+		t.Error("expected error for unknown collector")
+	}
+	_, err = NewElasticsearchCollector(logger, []string{},
+		WithElasticsearchURL(u),
+		WithHTTPClient(http.DefaultClient),
+		WithClusterInfoProvider(infoProvider),
+		WithNodeSelector([]string{"_nodes/../_cluster"}),
+	)
+	if err == nil {
+		t.Error("expected error for invalid node selector")
+	}
 }
@@ -0,0 +1,96 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var scrapeErrorsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "scrape", "errors_total"),
+	"elasticsearch_exporter: Number of failed collector scrapes by reason.",
+	[]string{"collector", "reason"},
+	nil,
+)
+
+// errDecode marks errors that occurred while decoding a response body.
+var errDecode = errors.New("failed to decode response")
+
+// httpStatusError is returned when Elasticsearch responds with a status code
+// other than 200 OK.
+type httpStatusError struct {
+	code int
+	// url is the request URL with its password redacted.
+	url string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("HTTP Request to %s failed with code %d", e.url, e.code)
+}
+
+// errorReason classifies a collector error into the reason label of
+// elasticsearch_scrape_errors_total.
+func errorReason(err error) string {
+	var (
+		statusErr *httpStatusError
+		netErr    net.Error
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		authErr   x509.UnknownAuthorityError
+		certErr   x509.CertificateInvalidError
+		hostErr   x509.HostnameError
+		verifyErr *tls.CertificateVerificationError
+		recordErr tls.RecordHeaderError
+		alertErr  tls.AlertError
+	)
+
+	switch {
+	case errors.Is(err, ErrNoData):
+		return "no_data"
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.code == http.StatusUnauthorized:
+			return "http_401"
+		case statusErr.code == http.StatusForbidden:
+			return "http_403"
+		case statusErr.code == http.StatusTooManyRequests:
+			return "http_429"
+		case statusErr.code >= 500:
+			return "http_5xx"
+		}
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused"
+	case errors.As(err, &authErr), errors.As(err, &certErr), errors.As(err, &hostErr),
+		errors.As(err, &verifyErr), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return "tls"
+	case errors.Is(err, errDecode), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "decode"
+	}
+	return "other"
+}
@@ -0,0 +1,152 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestErrorReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		delay  time.Duration
+		want   string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, want: "http_401"},
+		{name: "forbidden", status: http.StatusForbidden, want: "http_403"},
+		{name: "too many requests", status: http.StatusTooManyRequests, want: "http_429"},
+		{name: "server error", status: http.StatusServiceUnavailable, want: "http_5xx"},
+		{name: "not found", status: http.StatusNotFound, want: "other"},
+		{name: "invalid json", status: http.StatusOK, body: `{"status":`, want: "decode"},
+		{name: "wrong type", status: http.StatusOK, body: `{"status":1}`, want: "decode"},
+		{name: "timeout", status: http.StatusOK, body: `{}`, delay: time.Second, want: "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(tt.delay):
+				case <-r.Context().Done():
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer ts.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			var target struct {
+				Status string `json:"status"`
+			}
+			err := getAndDecodeURL(ctx, http.DefaultClient, promslog.NewNopLogger(), ts.URL, &target)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errorReason(fmt.Errorf("failed to fetch: %w", err)); got != tt.want {
+				t.Errorf("expected reason %q, got %q (err: %s)", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestErrorReasonTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	refusedURL := ts.URL
+	ts.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "connection refused", url: refusedURL, want: "connection_refused"},
+		{name: "untrusted certificate", url: tlsServer.URL, want: "tls"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fetchURL(context.Background(), http.DefaultClient, promslog.NewNopLogger(), tt.url, nil)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errorReason(err); got != tt.want {
+				t.Errorf("expected reason %q, got %q (err: %s)", tt.want, got, err)
+			}
+		})
+	}
+
+	if got := errorReason(ErrNoData); got != "no_data" {
+		t.Errorf("expected reason %q, got %q", "no_data", got)
+	}
+}
+
+func TestHTTPStatusErrorMessage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	u := strings.Replace(ts.URL, "http://", "http://metrics:s3cr3t@", 1) + "/_cluster/health"
+	err := fetchURL(context.Background(), http.DefaultClient, promslog.NewNopLogger(), u, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := "HTTP Request to " + strings.Replace(u, "s3cr3t", "xxxxx", 1) + " failed with code 403"
+	if err.Error() != want {
+		t.Errorf("expected error %q, got %q", want, err)
+	}
+}
+
+// failingCollector always fails with err.
+type failingCollector struct {
+	err error
+}
+
+func (c failingCollector) Update(context.Context, UpdateContext, chan<- prometheus.Metric) error {
+	return c.err
+}
+
+func TestCollectorRunnerErrors(t *testing.T) {
+	r := newCollectorRunner("failing", failingCollector{err: &httpStatusError{code: http.StatusForbidden}}, collectorSchedule{mode: modeSync}, promslog.NewNopLogger())
+	e := &ElasticsearchCollector{runners: map[string]*collectorRunner{"failing": r}}
+
+	for range 2 {
+		collectRunner(r)
+	}
+
+	want := `# HELP elasticsearch_scrape_errors_total elasticsearch_exporter: Number of failed collector scrapes by reason.
+# TYPE elasticsearch_scrape_errors_total counter
+elasticsearch_scrape_errors_total{collector="failing",reason="http_403"} 3
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(want), "elasticsearch_scrape_errors_total"); err != nil {
+		t.Fatal(err)
+	}
+}
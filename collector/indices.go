@@ -16,19 +16,39 @@ package collector
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
-	"path"
 	"sort"
 	"strconv"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
+)
 
-	"github.com/prometheus-community/elasticsearch_exporter/pkg/clusterinfo"
+// esExportIndices, esExportShards and esExportIndexAliases are globals because
+// the collector interface doesn't expose any way to take constructor args.
+var (
+	esExportIndices      bool
+	esExportShards       bool
+	esExportIndexAliases bool
 )
 
+func init() {
+	kingpin.Flag("es.indices",
+		"Export stats for indices in the cluster (deprecated, use --collector.indices).").
+		Default("false").Action(legacyFlagAction(&esExportIndices, "indices", "shards")).BoolVar(&esExportIndices)
+	kingpin.Flag("es.shards",
+		"Export stats for shards in the cluster (implies --collector.indices).").
+		Default("false").Action(legacyFlagAction(&esExportShards, "indices", "shards")).BoolVar(&esExportShards)
+	kingpin.Flag("es.aliases",
+		"Export informational alias metrics.").
+		Default("true").BoolVar(&esExportIndexAliases)
+	registerCollector("indices", defaultDisabled, NewIndices)
+}
+
 var (
 	indicesLabels = []string{"index", "cluster"}
 
@@ -455,145 +475,34 @@ var (
 
 type labels struct {
 	keys   func(...string) []string
-	values func(*clusterinfo.Response, ...string) []string
+	values func(string, ...string) []string
 }
 
 // Indices information struct
 type Indices struct {
-	logger          *slog.Logger
-	client          *http.Client
-	url             *url.URL
-	shards          bool
-	aliases         bool
-	clusterInfoCh   chan *clusterinfo.Response
-	lastClusterInfo *clusterinfo.Response
+	logger  *slog.Logger
+	client  *http.Client
+	url     *url.URL
+	shards  bool
+	aliases bool
 }
 
 // NewIndices defines Indices Prometheus metrics
-func NewIndices(logger *slog.Logger, client *http.Client, url *url.URL, shards bool, includeAliases bool) *Indices {
-	indices := &Indices{
-		logger:        logger,
-		client:        client,
-		url:           url,
-		shards:        shards,
-		aliases:       includeAliases,
-		clusterInfoCh: make(chan *clusterinfo.Response),
-		lastClusterInfo: &clusterinfo.Response{
-			ClusterName: "unknown_cluster",
-		},
-	}
-
-	// start go routine to fetch clusterinfo updates and save them to lastClusterinfo
-	go func() {
-		logger.Debug("starting cluster info receive loop")
-		for ci := range indices.clusterInfoCh {
-			if ci != nil {
-				logger.Debug("received cluster info update", "cluster", ci.ClusterName)
-				indices.lastClusterInfo = ci
-			}
-		}
-		logger.Debug("exiting cluster info receive loop")
-	}()
-	return indices
-}
-
-// ClusterLabelUpdates returns a pointer to a channel to receive cluster info updates. It implements the
-// (not exported) clusterinfo.consumer interface
-func (i *Indices) ClusterLabelUpdates() *chan *clusterinfo.Response {
-	return &i.clusterInfoCh
-}
-
-// String implements the stringer interface. It is part of the clusterinfo.consumer interface
-func (i *Indices) String() string {
-	return namespace + "indices"
+func NewIndices(logger *slog.Logger, url *url.URL, client *http.Client) (Collector, error) {
+	return &Indices{
+		logger:  logger,
+		client:  client,
+		url:     url,
+		shards:  esExportShards,
+		aliases: esExportIndexAliases,
+	}, nil
 }
 
-// Describe add Indices metrics descriptions
-func (i *Indices) Describe(ch chan<- *prometheus.Desc) {
-	ch <- indicesDocsPrimary
-	ch <- indicesDeletedDocsPrimary
-	ch <- indicesDocsTotal
-	ch <- indicesDeletedDocsTotal
-	ch <- indicesStoreSizeBytesPrimary
-	ch <- indicesStoreSizeBytesTotal
-	ch <- indicesSegmentCountPrimary
-	ch <- indicesSegmentCountTotal
-	ch <- indicesSegmentMemoryBytesPrimary
-	ch <- indicesSegmentMemoryBytesTotal
-	ch <- indicesSegmentTermsMemoryPrimary
-	ch <- indicesSegmentTermsMemoryTotal
-	ch <- indicesSegmentFieldsMemoryBytesPrimary
-	ch <- indicesSegmentFieldsMemoryBytesTotal
-	ch <- indicesSegmentTermVectorsMemoryPrimary
-	ch <- indicesSegmentTermVectorsMemoryTotal
-	ch <- indicesSegmentNormsMemoryPrimary
-	ch <- indicesSegmentNormsMemoryTotal
-	ch <- indicesSegmentPointsMemoryPrimary
-	ch <- indicesSegmentPointsMemoryTotal
-	ch <- indicesSegmentDocValuesMemoryPrimary
-	ch <- indicesSegmentDocValuesMemoryTotal
-	ch <- indicesSegmentIndexWriterMemoryPrimary
-	ch <- indicesSegmentIndexWriterMemoryTotal
-	ch <- indicesSegmentVersionMapMemoryPrimary
-	ch <- indicesSegmentVersionMapMemoryTotal
-	ch <- indicesSegmentFBSMemoryPrimary
-	ch <- indicesSegmentFBSMemoryTotal
-	ch <- indicesCompletionPrimary
-	ch <- indicesCompletionTotal
-	ch <- indicesSearchQueryTimeTotal
-	ch <- indicesActiveQueries
-	ch <- indicesSearchQueryTotal
-	ch <- indicesSearchFetchTimeTotal
-	ch <- indicesSearchFetchTotal
-	ch <- indicesSearchScrollTimeTotal
-	ch <- indicesSearchScrollCurrent
-	ch <- indicesSearchScrollTotal
-	ch <- indicesSearchSuggestTimeTotal
-	ch <- indicesSearchSuggestTotal
-	ch <- indicesIndexingTimeTotal
-	ch <- indicesIndexCurrent
-	ch <- indicesIndexingIndexTotal
-	ch <- indicesIndexingDeleteSecondsTotal
-	ch <- indicesIndexingDeleteTotal
-	ch <- indicesIndexingNoopUpdateTotal
-	ch <- indicesIndexingThrottleSecondsTotal
-	ch <- indicesIndexingIndexFailed
-	ch <- indicesIndexingDeleteCurrent
-	ch <- indicesIndexingWriteLoad
-	ch <- indicesIndexingIsThrottled
-	ch <- indicesGetTimeTotal
-	ch <- indicesGetTotal
-	ch <- indicesMergeTimeTotal
-	ch <- indicesMergeTotal
-	ch <- indicesMergeThrottleTimeTotal
-	ch <- indicesMergeStoppedTimeTotal
-	ch <- indicesMergeAutoThrottleBytesTotal
-	ch <- indicesRefreshTimeTotal
-	ch <- indicesRefreshExternalTimeTotal
-	ch <- indicesRefreshExternalTotal
-	ch <- indicesRefreshTotal
-	ch <- indicesFlushTimeTotal
-	ch <- indicesFlushTotal
-	ch <- indicesWarmerTimeTotal
-	ch <- indicesWarmerTotal
-	ch <- indicesQueryCacheMemoryTotal
-	ch <- indicesQueryCacheSize
-	ch <- indicesQueryCacheHits
-	ch <- indicesQueryCacheMisses
-	ch <- indicesQueryCacheCaches
-	ch <- indicesQueryCacheEvictions
-	ch <- indicesRequestCacheMemory
-	ch <- indicesRequestCacheHits
-	ch <- indicesRequestCacheMisses
-	ch <- indicesRequestCacheEvictions
-	ch <- indicesFielddataMemory
-	ch <- indicesFielddataEvictions
-
-	ch <- indicesAliases
-
-	ch <- indicesShardDocs
-	ch <- indicesShardDocsDeleted
-	ch <- indicesShardStoreSizeBytes
+// configure implements targetConfigurer.
+func (i *Indices) configure(s targetSettings) {
+	if s.shardLevel != nil {
+		i.shards = *s.shardLevel
+	}
 }
 
 // fetchAliases retrieves index -> alias-name-list mappings from the _alias
@@ -687,42 +596,15 @@ func streamIndexStats(r io.Reader, emit func(name string, indexStats IndexStatsI
 	return nil
 }
 
-// getClusterName returns the cluster name. If no clusterinfo retriever is
-// attached (e.g. /probe mode) it performs a lightweight call to the root
-// endpoint once and caches the result.
-func (i *Indices) getClusterName() string {
-	if i.lastClusterInfo != nil && i.lastClusterInfo.ClusterName != "unknown_cluster" {
-		return i.lastClusterInfo.ClusterName
-	}
-	u := *i.url
-	u.Path = path.Join(u.Path, "/")
-	resp, err := i.client.Get(u.String())
-	if err == nil {
-		defer resp.Body.Close()
-		if resp.StatusCode == http.StatusOK {
-			var root struct {
-				ClusterName string `json:"cluster_name"`
-			}
-			if err := json.NewDecoder(resp.Body).Decode(&root); err == nil && root.ClusterName != "" {
-				i.lastClusterInfo = &clusterinfo.Response{ClusterName: root.ClusterName}
-				return root.ClusterName
-			}
-		}
-	}
-	return "unknown_cluster"
-}
-
-// Collect gets Indices metric values
-func (i *Indices) Collect(ch chan<- prometheus.Metric) {
-	ctx := context.TODO()
-	clusterName := i.getClusterName()
+// Update gets Indices metric values
+func (i *Indices) Update(ctx context.Context, uc UpdateContext, ch chan<- prometheus.Metric) error {
+	clusterName := getClusterName(ctx, uc)
 
 	// Alias stats
 	if i.aliases {
 		aliases, err := i.fetchAliases(ctx)
 		if err != nil {
-			i.logger.Error("error getting alias information", "err", err)
-			return
+			return fmt.Errorf("error getting alias information: %w", err)
 		}
 
 		for indexName, aliasList := range aliases {
@@ -741,12 +623,10 @@ func (i *Indices) Collect(ch chan<- prometheus.Metric) {
 
 	// Index stats
 	if err := i.streamAndEmitIndexStats(ctx, ch, clusterName); err != nil {
-		i.logger.Warn(
-			"failed to fetch and decode index stats",
-			"err", err,
-		)
-		return
+		return fmt.Errorf("failed to fetch and decode index stats: %w", err)
 	}
+
+	return nil
 }
 
 // emitIndexMetrics writes all per-index metrics for a single index to ch.
@@ -14,17 +14,27 @@
 package collector
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"path"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// esExportIndicesMappings is kept for the deprecated es.indices_mappings flag.
+var esExportIndicesMappings bool
+
+func init() {
+	kingpin.Flag("es.indices_mappings",
+		"Export stats for mappings of all indices of the cluster (deprecated, use --collector.indices-mappings).").
+		Default("false").Action(legacyFlagAction(&esExportIndicesMappings, "indices-mappings")).BoolVar(&esExportIndicesMappings)
+	registerCollector("indices-mappings", defaultDisabled, NewIndicesMappings)
+}
+
 var defaultIndicesMappingsLabels = []string{"index"}
 
 type indicesMappingsMetric struct {
@@ -43,7 +53,7 @@ type IndicesMappings struct {
 }
 
 // NewIndicesMappings defines Indices IndexMappings Prometheus metrics
-func NewIndicesMappings(logger *slog.Logger, client *http.Client, url *url.URL) *IndicesMappings {
+func NewIndicesMappings(logger *slog.Logger, url *url.URL, client *http.Client) (Collector, error) {
 	subsystem := "indices_mappings_stats"
 
 	return &IndicesMappings{
@@ -64,7 +74,7 @@ func NewIndicesMappings(logger *slog.Logger, client *http.Client, url *url.URL)
 				},
 			},
 		},
-	}
+	}, nil
 }
 
 func countFieldsRecursive(properties IndexMappingProperties, fieldCounter float64) float64 {
@@ -93,58 +103,23 @@ func countFieldsRecursive(properties IndexMappingProperties, fieldCounter float6
 	return fieldCounter
 }
 
-// Describe add Snapshots metrics descriptions
-func (im *IndicesMappings) Describe(ch chan<- *prometheus.Desc) {
-	for _, metric := range im.metrics {
-		ch <- metric.Desc
-	}
-}
-
-func (im *IndicesMappings) getAndParseURL(u *url.URL) (*IndicesMappingsResponse, error) {
+func (im *IndicesMappings) fetchAndDecodeIndicesMappings(ctx context.Context) (*IndicesMappingsResponse, error) {
 	var imr IndicesMappingsResponse
 
-	res, err := im.client.Get(u.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
-	}
-	defer func() {
-		if cerr := res.Body.Close(); cerr != nil {
-			im.logger.Warn("failed to close response body", "err", cerr)
-		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
-	}
-
-	bts, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := json.Unmarshal(bts, &imr); err != nil {
+	u := *im.url
+	u.Path = path.Join(u.Path, "/_all/_mappings")
+	if err := getAndDecodeURL(ctx, im.client, im.logger, u.String(), &imr); err != nil {
 		return nil, err
 	}
 
 	return &imr, nil
 }
 
-func (im *IndicesMappings) fetchAndDecodeIndicesMappings() (*IndicesMappingsResponse, error) {
-	u := *im.url
-	u.Path = path.Join(u.Path, "/_all/_mappings")
-	return im.getAndParseURL(&u)
-}
-
-// Collect gets all indices mappings metric values
-func (im *IndicesMappings) Collect(ch chan<- prometheus.Metric) {
-	indicesMappingsResponse, err := im.fetchAndDecodeIndicesMappings()
+// Update gets all indices mappings metric values
+func (im *IndicesMappings) Update(ctx context.Context, _ UpdateContext, ch chan<- prometheus.Metric) error {
+	indicesMappingsResponse, err := im.fetchAndDecodeIndicesMappings(ctx)
 	if err != nil {
-		im.logger.Warn(
-			"failed to fetch and decode cluster mappings stats",
-			"err", err,
-		)
-		return
+		return fmt.Errorf("failed to fetch and decode cluster mappings stats: %w", err)
 	}
 
 	for _, metric := range im.metrics {
@@ -157,4 +132,6 @@ func (im *IndicesMappings) Collect(ch chan<- prometheus.Metric) {
 			)
 		}
 	}
+
+	return nil
 }
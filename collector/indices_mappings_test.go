@@ -113,12 +113,12 @@ elasticsearch_indices_mappings_stats_fields{index="test-data-2023.01.20"} 40
 				t.Fatal(err)
 			}
 
-			c := NewIndicesMappings(promslog.NewNopLogger(), http.DefaultClient, u)
+			c, err := NewIndicesMappings(promslog.NewNopLogger(), u, http.DefaultClient)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			if err := testutil.CollectAndCompare(c, strings.NewReader(tt.want)); err != nil {
+			if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(tt.want)); err != nil {
 				t.Fatal(err)
 			}
 		})
@@ -14,18 +14,28 @@
 package collector
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// esExportIndicesSettings is kept for the deprecated es.indices_settings flag.
+var esExportIndicesSettings bool
+
+func init() {
+	kingpin.Flag("es.indices_settings",
+		"Export stats for settings of all indices of the cluster (deprecated, use --collector.indices-settings).").
+		Default("false").Action(legacyFlagAction(&esExportIndicesSettings, "indices-settings")).BoolVar(&esExportIndicesSettings)
+	registerCollector("indices-settings", defaultDisabled, NewIndicesSettings)
+}
+
 // IndicesSettings information struct
 type IndicesSettings struct {
 	logger *slog.Logger
@@ -50,7 +60,7 @@ type indicesSettingsMetric struct {
 }
 
 // NewIndicesSettings defines Indices Settings Prometheus metrics
-func NewIndicesSettings(logger *slog.Logger, client *http.Client, url *url.URL) *IndicesSettings {
+func NewIndicesSettings(logger *slog.Logger, url *url.URL, client *http.Client) (Collector, error) {
 	return &IndicesSettings{
 		logger: logger,
 		client: client,
@@ -123,51 +133,14 @@ func NewIndicesSettings(logger *slog.Logger, client *http.Client, url *url.URL)
 				},
 			},
 		},
-	}
-}
-
-// Describe add Snapshots metrics descriptions
-func (cs *IndicesSettings) Describe(ch chan<- *prometheus.Desc) {
-	ch <- cs.readOnlyIndices.Desc()
-
-	for _, metric := range cs.metrics {
-		ch <- metric.Desc
-	}
-}
-
-func (cs *IndicesSettings) getAndParseURL(u *url.URL, data interface{}) error {
-	res, err := cs.client.Get(u.String())
-	if err != nil {
-		return fmt.Errorf("failed to get from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
-	}
-
-	defer func() {
-		if cerr := res.Body.Close(); cerr != nil {
-			cs.logger.Warn("failed to close response body", "err", cerr)
-		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
-	}
-
-	bts, err := io.ReadAll(res.Body)
-	if err != nil {
-		return err
-	}
-
-	if err := json.Unmarshal(bts, data); err != nil {
-		return err
-	}
-	return nil
+	}, nil
 }
 
-func (cs *IndicesSettings) fetchAndDecodeIndicesSettings() (IndicesSettingsResponse, error) {
+func (cs *IndicesSettings) fetchAndDecodeIndicesSettings(ctx context.Context) (IndicesSettingsResponse, error) {
 	u := *cs.url
 	u.Path = path.Join(u.Path, "/_all/_settings")
 	var asr IndicesSettingsResponse
-	err := cs.getAndParseURL(&u, &asr)
+	err := getAndDecodeURL(ctx, cs.client, cs.logger, u.String(), &asr)
 	if err != nil {
 		return asr, err
 	}
@@ -175,16 +148,12 @@ func (cs *IndicesSettings) fetchAndDecodeIndicesSettings() (IndicesSettingsRespo
 	return asr, err
 }
 
-// Collect gets all indices settings metric values
-func (cs *IndicesSettings) Collect(ch chan<- prometheus.Metric) {
-	asr, err := cs.fetchAndDecodeIndicesSettings()
+// Update gets all indices settings metric values
+func (cs *IndicesSettings) Update(ctx context.Context, _ UpdateContext, ch chan<- prometheus.Metric) error {
+	asr, err := cs.fetchAndDecodeIndicesSettings(ctx)
 	if err != nil {
 		cs.readOnlyIndices.Set(0)
-		cs.logger.Warn(
-			"failed to fetch and decode cluster settings stats",
-			"err", err,
-		)
-		return
+		return fmt.Errorf("failed to fetch and decode cluster settings stats: %w", err)
 	}
 
 	var c int
@@ -204,4 +173,6 @@ func (cs *IndicesSettings) Collect(ch chan<- prometheus.Metric) {
 	cs.readOnlyIndices.Set(float64(c))
 
 	ch <- cs.readOnlyIndices
+
+	return nil
 }
@@ -2238,14 +2238,15 @@ func TestIndices(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			c := NewIndices(promslog.NewNopLogger(), http.DefaultClient, u, false, true)
+			c, err := NewIndices(promslog.NewNopLogger(), u, http.DefaultClient)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			c.shards = tt.shards
+			c.(*Indices).shards = tt.shards
+			c.(*Indices).aliases = true
 
-			if err := testutil.CollectAndCompare(c, strings.NewReader(tt.want)); err != nil {
+			if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(tt.want)); err != nil {
 				t.Fatal(err)
 			}
 		})
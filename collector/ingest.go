@@ -0,0 +1,236 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	ingestNodeLabels      = []string{"cluster", "host", "name"}
+	ingestPipelineLabels  = append(ingestNodeLabels, "pipeline")
+	ingestProcessorLabels = append(ingestPipelineLabels, "type", "tag")
+
+	ingestDocumentsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest", "documents_total"),
+		"Number of documents ingested by the node",
+		ingestNodeLabels, nil,
+	)
+	ingestTimeSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest", "time_seconds_total"),
+		"Time spent ingesting documents on the node",
+		ingestNodeLabels, nil,
+	)
+	ingestDocumentsCurrent = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest", "documents_current"),
+		"Number of documents currently being ingested by the node",
+		ingestNodeLabels, nil,
+	)
+	ingestFailedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest", "failed_total"),
+		"Number of failed ingest operations on the node",
+		ingestNodeLabels, nil,
+	)
+
+	ingestPipelineDocumentsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest_pipeline", "documents_total"),
+		"Number of documents ingested by the pipeline",
+		ingestPipelineLabels, nil,
+	)
+	ingestPipelineTimeSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest_pipeline", "time_seconds_total"),
+		"Time spent ingesting documents in the pipeline",
+		ingestPipelineLabels, nil,
+	)
+	ingestPipelineDocumentsCurrent = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest_pipeline", "documents_current"),
+		"Number of documents currently being ingested by the pipeline",
+		ingestPipelineLabels, nil,
+	)
+	ingestPipelineFailedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest_pipeline", "failed_total"),
+		"Number of failed ingest operations in the pipeline",
+		ingestPipelineLabels, nil,
+	)
+
+	ingestProcessorDocumentsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest_processor", "documents_total"),
+		"Number of documents processed by the processor",
+		ingestProcessorLabels, nil,
+	)
+	ingestProcessorTimeSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest_processor", "time_seconds_total"),
+		"Time spent in the processor",
+		ingestProcessorLabels, nil,
+	)
+	ingestProcessorFailedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "ingest_processor", "failed_total"),
+		"Number of failed operations of the processor",
+		ingestProcessorLabels, nil,
+	)
+)
+
+func init() {
+	registerCollector("ingest", defaultDisabled, NewIngest)
+}
+
+// Ingest information struct
+type Ingest struct {
+	logger *slog.Logger
+	hc     *http.Client
+	u      *url.URL
+}
+
+// NewIngest defines Ingest Prometheus metrics
+func NewIngest(logger *slog.Logger, u *url.URL, hc *http.Client) (Collector, error) {
+	return &Ingest{
+		logger: logger,
+		hc:     hc,
+		u:      u,
+	}, nil
+}
+
+// IngestStatsResponse is a representation of the ingest section of the Elasticsearch node stats
+type IngestStatsResponse struct {
+	ClusterName string                             `json:"cluster_name"`
+	Nodes       map[string]IngestStatsNodeResponse `json:"nodes"`
+}
+
+// IngestStatsNodeResponse defines the ingest stats of a single node
+type IngestStatsNodeResponse struct {
+	Name   string `json:"name"`
+	Host   string `json:"host"`
+	Ingest struct {
+		Total     IngestStatsCountersResponse            `json:"total"`
+		Pipelines map[string]IngestStatsPipelineResponse `json:"pipelines"`
+	} `json:"ingest"`
+}
+
+// IngestStatsCountersResponse defines the counters reported for nodes, pipelines and processors
+type IngestStatsCountersResponse struct {
+	Count        int64 `json:"count"`
+	TimeInMillis int64 `json:"time_in_millis"`
+	Current      int64 `json:"current"`
+	Failed       int64 `json:"failed"`
+}
+
+// IngestStatsPipelineResponse defines the ingest stats of a pipeline
+type IngestStatsPipelineResponse struct {
+	IngestStatsCountersResponse
+	// Processors holds one single-entry map per processor, keyed by the
+	// processor type, followed by its tag if it has one.
+	Processors []map[string]IngestStatsProcessorResponse `json:"processors"`
+}
+
+// IngestStatsProcessorResponse defines the ingest stats of a processor
+type IngestStatsProcessorResponse struct {
+	Type  string                      `json:"type"`
+	Stats IngestStatsCountersResponse `json:"stats"`
+}
+
+// processorKey identifies a processor within a pipeline.
+type processorKey struct {
+	typ string
+	tag string
+}
+
+// processorTag extracts the tag from a processor name of the form
+// type[:tag].
+func processorTag(name, typ string) string {
+	if tag, ok := strings.CutPrefix(name, typ+":"); ok {
+		return tag
+	}
+	return ""
+}
+
+func (i *Ingest) Update(ctx context.Context, _ UpdateContext, ch chan<- prometheus.Metric) error {
+	var isr IngestStatsResponse
+
+	u := i.u.ResolveReference(&url.URL{Path: "/_nodes/stats/ingest"})
+	q := u.Query()
+	q.Set("filter_path", "cluster_name,nodes.*.name,nodes.*.host,nodes.*.ingest")
+	u.RawQuery = q.Encode()
+
+	if err := getAndDecodeURL(ctx, i.hc, i.logger, u.String(), &isr); err != nil {
+		return err
+	}
+
+	for _, node := range isr.Nodes {
+		nodeLabels := []string{isr.ClusterName, node.Host, node.Name}
+		emitIngestCounters(ch, node.Ingest.Total,
+			ingestDocumentsTotal, ingestTimeSeconds, ingestDocumentsCurrent, ingestFailedTotal, nodeLabels)
+
+		for pipeline, stats := range node.Ingest.Pipelines {
+			pipelineLabels := append(nodeLabels, pipeline)
+			emitIngestCounters(ch, stats.IngestStatsCountersResponse,
+				ingestPipelineDocumentsTotal, ingestPipelineTimeSeconds, ingestPipelineDocumentsCurrent, ingestPipelineFailedTotal, pipelineLabels)
+
+			// Processors of the same type without a tag cannot be told
+			// apart, so their stats are summed up.
+			processors := map[processorKey]IngestStatsCountersResponse{}
+			var order []processorKey
+			for _, p := range stats.Processors {
+				for name, ps := range p {
+					key := processorKey{typ: ps.Type, tag: processorTag(name, ps.Type)}
+					sum, ok := processors[key]
+					if !ok {
+						order = append(order, key)
+					}
+					sum.Count += ps.Stats.Count
+					sum.TimeInMillis += ps.Stats.TimeInMillis
+					sum.Failed += ps.Stats.Failed
+					processors[key] = sum
+				}
+			}
+			for _, key := range order {
+				sum := processors[key]
+				labels := append(pipelineLabels[:len(pipelineLabels):len(pipelineLabels)], key.typ, key.tag)
+				ch <- prometheus.MustNewConstMetric(
+					ingestProcessorDocumentsTotal,
+					prometheus.CounterValue,
+					float64(sum.Count),
+					labels...,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					ingestProcessorTimeSeconds,
+					prometheus.CounterValue,
+					float64(sum.TimeInMillis)/1000,
+					labels...,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					ingestProcessorFailedTotal,
+					prometheus.CounterValue,
+					float64(sum.Failed),
+					labels...,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// emitIngestCounters writes the counters shared by nodes and pipelines to ch.
+func emitIngestCounters(ch chan<- prometheus.Metric, stats IngestStatsCountersResponse, count, time, current, failed *prometheus.Desc, labels []string) {
+	ch <- prometheus.MustNewConstMetric(count, prometheus.CounterValue, float64(stats.Count), labels...)
+	ch <- prometheus.MustNewConstMetric(time, prometheus.CounterValue, float64(stats.TimeInMillis)/1000, labels...)
+	ch <- prometheus.MustNewConstMetric(current, prometheus.GaugeValue, float64(stats.Current), labels...)
+	ch <- prometheus.MustNewConstMetric(failed, prometheus.CounterValue, float64(stats.Failed), labels...)
+}
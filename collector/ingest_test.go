@@ -0,0 +1,116 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestIngest(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{
+			name: "8.11.0",
+			file: "../fixtures/ingest/8.11.0.json",
+			want: `# HELP elasticsearch_ingest_documents_current Number of documents currently being ingested by the node
+            # TYPE elasticsearch_ingest_documents_current gauge
+            elasticsearch_ingest_documents_current{cluster="logging",host="10.0.1.11",name="es-ingest-1"} 2
+            elasticsearch_ingest_documents_current{cluster="logging",host="10.0.1.21",name="es-master-1"} 0
+            # HELP elasticsearch_ingest_documents_total Number of documents ingested by the node
+            # TYPE elasticsearch_ingest_documents_total counter
+            elasticsearch_ingest_documents_total{cluster="logging",host="10.0.1.11",name="es-ingest-1"} 1523
+            elasticsearch_ingest_documents_total{cluster="logging",host="10.0.1.21",name="es-master-1"} 0
+            # HELP elasticsearch_ingest_failed_total Number of failed ingest operations on the node
+            # TYPE elasticsearch_ingest_failed_total counter
+            elasticsearch_ingest_failed_total{cluster="logging",host="10.0.1.11",name="es-ingest-1"} 7
+            elasticsearch_ingest_failed_total{cluster="logging",host="10.0.1.21",name="es-master-1"} 0
+            # HELP elasticsearch_ingest_pipeline_documents_current Number of documents currently being ingested by the pipeline
+            # TYPE elasticsearch_ingest_pipeline_documents_current gauge
+            elasticsearch_ingest_pipeline_documents_current{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx"} 2
+            elasticsearch_ingest_pipeline_documents_current{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="xpack_monitoring_7"} 0
+            # HELP elasticsearch_ingest_pipeline_documents_total Number of documents ingested by the pipeline
+            # TYPE elasticsearch_ingest_pipeline_documents_total counter
+            elasticsearch_ingest_pipeline_documents_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx"} 1500
+            elasticsearch_ingest_pipeline_documents_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="xpack_monitoring_7"} 23
+            # HELP elasticsearch_ingest_pipeline_failed_total Number of failed ingest operations in the pipeline
+            # TYPE elasticsearch_ingest_pipeline_failed_total counter
+            elasticsearch_ingest_pipeline_failed_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx"} 7
+            elasticsearch_ingest_pipeline_failed_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="xpack_monitoring_7"} 0
+            # HELP elasticsearch_ingest_pipeline_time_seconds_total Time spent ingesting documents in the pipeline
+            # TYPE elasticsearch_ingest_pipeline_time_seconds_total counter
+            elasticsearch_ingest_pipeline_time_seconds_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx"} 2.3
+            elasticsearch_ingest_pipeline_time_seconds_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="xpack_monitoring_7"} 0.08
+            # HELP elasticsearch_ingest_processor_documents_total Number of documents processed by the processor
+            # TYPE elasticsearch_ingest_processor_documents_total counter
+            elasticsearch_ingest_processor_documents_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx",tag="",type="grok"} 1500
+            elasticsearch_ingest_processor_documents_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx",tag="",type="rename"} 2986
+            elasticsearch_ingest_processor_documents_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx",tag="add-env",type="set"} 1493
+            # HELP elasticsearch_ingest_processor_failed_total Number of failed operations of the processor
+            # TYPE elasticsearch_ingest_processor_failed_total counter
+            elasticsearch_ingest_processor_failed_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx",tag="",type="grok"} 7
+            elasticsearch_ingest_processor_failed_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx",tag="",type="rename"} 0
+            elasticsearch_ingest_processor_failed_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx",tag="add-env",type="set"} 0
+            # HELP elasticsearch_ingest_processor_time_seconds_total Time spent in the processor
+            # TYPE elasticsearch_ingest_processor_time_seconds_total counter
+            elasticsearch_ingest_processor_time_seconds_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx",tag="",type="grok"} 1.9
+            elasticsearch_ingest_processor_time_seconds_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx",tag="",type="rename"} 0.035
+            elasticsearch_ingest_processor_time_seconds_total{cluster="logging",host="10.0.1.11",name="es-ingest-1",pipeline="logs-nginx",tag="add-env",type="set"} 0.012
+            # HELP elasticsearch_ingest_time_seconds_total Time spent ingesting documents on the node
+            # TYPE elasticsearch_ingest_time_seconds_total counter
+            elasticsearch_ingest_time_seconds_total{cluster="logging",host="10.0.1.11",name="es-ingest-1"} 2.38
+            elasticsearch_ingest_time_seconds_total{cluster="logging",host="10.0.1.21",name="es-master-1"} 0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(w, f)
+			}))
+			defer ts.Close()
+
+			u, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := NewIngest(promslog.NewNopLogger(), u, http.DefaultClient)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(tt.want)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -14,17 +14,108 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"math"
 	"net/http"
 	"net/url"
 	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/prometheus-community/elasticsearch_exporter/config"
+)
+
+// esAllNodes, esNodes and the es.sniff settings are globals because the
+// collector interface doesn't expose any way to take constructor args.
+var (
+	esAllNodes      bool
+	esNodes         []string
+	esSniff         bool
+	esSniffParallel int
+	esSniffTimeout  time.Duration
+	esNodesMetrics  []string
+	esNodesActions  bool
+
+	esAdaptiveSelection           bool
+	esAdaptiveSelectionMaxTargets int
 )
 
+// nodeMetricGroups maps the metric groups that can be selected with
+// nodes.metrics to their field in the nodes stats response. The group names
+// are the metrics of the nodes stats API.
+var nodeMetricGroups = map[string]string{
+	"adaptive_selection": "adaptive_selection",
+	"breaker":            "breakers",
+	"discovery":          "discovery",
+	"fs":                 "fs",
+	"http":               "http",
+	"indexing_pressure":  "indexing_pressure",
+	"indices":            "indices",
+	"jvm":                "jvm",
+	"os":                 "os",
+	"process":            "process",
+	"script":             "script",
+	"thread_pool":        "thread_pool",
+	"transport":          "transport",
+}
+
+// nodeMetricGroupExtras lists the nodes stats API metrics that are queried
+// along with a group, whose response fields are named like the metric. Before
+// Elasticsearch 8.x the per-context script stats are only part of
+// script_cache, which was added in 7.9; older versions reject the request.
+var nodeMetricGroupExtras = map[string][]nodeMetricExtra{
+	"script": {{metric: "script_cache", since: semver.MustParse("7.9.0"), until: semver.MustParse("8.0.0")}},
+}
+
+// nodeMetricExtra is a nodes stats API metric that is only queried from
+// Elasticsearch versions in [since, until).
+type nodeMetricExtra struct {
+	metric       string
+	since, until semver.Version
+}
+
+func init() {
+	kingpin.Flag("es.all",
+		"Export stats for all nodes in the cluster. If used, this flag will override the flag es.node.").
+		Default("false").BoolVar(&esAllNodes)
+	kingpin.Flag("es.node",
+		"Node filter expression selecting the nodes whose metrics should be exposed, e.g. a node name, master:true or attr.zone:us-east-1a. Can be repeated or comma separated.").
+		Default("_local").StringsVar(&esNodes)
+	kingpin.Flag("es.sniff",
+		"Discover the nodes of the cluster with _nodes/http and query the stats of each node from the node itself. If used, this flag will override the flags es.all and es.node.").
+		Default("false").BoolVar(&esSniff)
+	kingpin.Flag("es.sniff.parallelism",
+		"Maximum number of nodes queried at the same time with es.sniff.").
+		Default("8").IntVar(&esSniffParallel)
+	kingpin.Flag("es.sniff.node-timeout",
+		"Timeout for querying the stats of a single node with es.sniff.").
+		Default("5s").DurationVar(&esSniffTimeout)
+	kingpin.Flag("nodes.metrics",
+		"Metric group of the nodes stats API to query, one of "+strings.Join(slices.Sorted(maps.Keys(nodeMetricGroups)), ", ")+". Can be repeated or comma separated. If not set, all groups are queried.").
+		StringsVar(&esNodesMetrics)
+	kingpin.Flag("nodes.transport-actions",
+		"Export the request and response size histograms of every transport action (Elasticsearch 8.x+). Adds two histograms per action and node.").
+		Default("false").BoolVar(&esNodesActions)
+	kingpin.Flag("nodes.adaptive-selection",
+		"Export the adaptive replica selection stats each node keeps about the nodes it sends searches to. Adds a series per pair of nodes.").
+		Default("false").BoolVar(&esAdaptiveSelection)
+	kingpin.Flag("nodes.adaptive-selection.max-targets",
+		"Maximum number of target nodes to export adaptive replica selection stats for per node, the ones with the highest average response time first. 0 means no limit.").
+		Default("10").IntVar(&esAdaptiveSelectionMaxTargets)
+	registerCollector("nodes", defaultEnabled, NewNodes)
+}
+
 func getRoles(node NodeStatsNodeResponse) map[string]bool {
 	// default settings (2.x) and map, which roles to consider
 	roles := map[string]bool{
@@ -63,7 +154,7 @@ func getRoles(node NodeStatsNodeResponse) map[string]bool {
 			}
 		}
 	}
-	if len(node.HTTP) == 0 {
+	if node.HTTP == nil {
 		roles["client"] = false
 	}
 	return roles
@@ -83,6 +174,62 @@ var nodesRolesMetric = prometheus.NewDesc(
 	append(defaultRoleLabels, "role"), nil,
 )
 
+var (
+	httpClientsMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "http", "clients"),
+		"Number of HTTP clients tracked by the node by state, closed clients are kept by Elasticsearch for a while",
+		append(defaultNodeLabels, "state"), nil,
+	)
+	httpClientRequestsMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "http", "client_requests"),
+		"Number of requests sent by the HTTP clients tracked by the node by state",
+		append(defaultNodeLabels, "state"), nil,
+	)
+	httpRouteRequestSizeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "http_route", "request_size_bytes"),
+		"Size of the HTTP requests to a REST route",
+		defaultHTTPRouteLabels, nil,
+	)
+	httpRouteResponseSizeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "http_route", "response_size_bytes"),
+		"Size of the HTTP responses of a REST route",
+		defaultHTTPRouteLabels, nil,
+	)
+
+	transportInboundHandlingTimeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "transport", "inbound_handling_time_seconds_bucket"),
+		"Cumulative number of inbound transport messages handled on the network thread within the upper bound le, Elasticsearch does not report the sum to build a histogram",
+		append(defaultNodeLabels, "le"), nil,
+	)
+	transportOutboundHandlingTimeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "transport", "outbound_handling_time_seconds_bucket"),
+		"Cumulative number of outbound transport messages sent on the network thread within the upper bound le, Elasticsearch does not report the sum to build a histogram",
+		append(defaultNodeLabels, "le"), nil,
+	)
+	transportActionRequestSizeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "transport_action", "request_size_bytes"),
+		"Size of the transport requests of an action",
+		defaultTransportActionLabels, nil,
+	)
+	transportActionResponseSizeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "transport_action", "response_size_bytes"),
+		"Size of the transport responses of an action",
+		defaultTransportActionLabels, nil,
+	)
+)
+
+var nodesResolvedMetric = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "nodes", "resolved"),
+	"Number of nodes matched by the node selector",
+	[]string{"cluster"}, nil,
+)
+
+var jvmMemoryPressureMetric = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "jvm", "memory_pressure"),
+	"Percentage of the old generation pool in use, as used by Elastic to alert on heap pressure",
+	defaultNodeLabels, nil,
+)
+
 var (
 	defaultNodeLabels               = []string{"cluster", "host", "name", "es_master_node", "es_data_node", "es_ingest_node", "es_client_node"}
 	defaultRoleLabels               = []string{"cluster", "host", "name", "node"}
@@ -92,6 +239,9 @@ var (
 	defaultFilesystemDataLabels     = append(defaultNodeLabels, "mount", "path")
 	defaultFilesystemIODeviceLabels = append(defaultNodeLabels, "device")
 	defaultCacheLabels              = append(defaultNodeLabels, "cache")
+	defaultHTTPRouteLabels          = append(defaultNodeLabels, "route")
+	defaultTransportActionLabels    = append(defaultNodeLabels, "action")
+	defaultScriptContextLabels      = append(defaultNodeLabels, "context")
 
 	defaultNodeLabelValues = func(cluster string, node NodeStatsNodeResponse) []string {
 		roles := getRoles(node)
@@ -123,6 +273,9 @@ var (
 )
 
 type nodeMetric struct {
+	// Group is the metric group of the nodes stats API the value is taken
+	// from, see nodeMetricGroups.
+	Group  string
 	Type   prometheus.ValueType
 	Desc   *prometheus.Desc
 	Value  func(node NodeStatsNodeResponse) float64
@@ -157,6 +310,29 @@ type threadPoolMetric struct {
 	Labels func(cluster string, node NodeStatsNodeResponse, breaker string) []string
 }
 
+type scriptContextMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(contextStats NodeStatsScriptContextResponse) float64
+	Labels func(cluster string, node NodeStatsNodeResponse, context string) []string
+}
+
+type tcpMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(tcpStats NodeStatsTCPResponse) float64
+	Labels func(cluster string, node NodeStatsNodeResponse) []string
+}
+
+// cgroupMetric is a metric of the control group stats. Value returns false if
+// the stats do not contain the value, e.g. when a memory value is empty.
+type cgroupMetric struct {
+	Type   prometheus.ValueType
+	Desc   *prometheus.Desc
+	Value  func(cgroupStats NodeStatsOSCgroupResponse) (float64, bool)
+	Labels func(cluster string, node NodeStatsNodeResponse) []string
+}
+
 type filesystemDataMetric struct {
 	Type   prometheus.ValueType
 	Desc   *prometheus.Desc
@@ -177,29 +353,65 @@ type Nodes struct {
 	client *http.Client
 	url    *url.URL
 	all    bool
-	node   string
+	nodes  []string
+
+	sniff         bool
+	sniffParallel int
+	sniffTimeout  time.Duration
+
+	// metrics are the metric groups to query, all groups if empty.
+	metrics []string
+	// transportActions enables the per transport action histograms.
+	transportActions bool
+	// adaptiveSelection enables the adaptive replica selection stats, for at
+	// most adaptiveSelectionMaxTargets target nodes per node if positive.
+	adaptiveSelection           bool
+	adaptiveSelectionMaxTargets int
 
 	nodeMetrics               []*nodeMetric
 	gcCollectionMetrics       []*gcCollectionMetric
 	breakerMetrics            []*breakerMetric
 	indexingPressureMetrics   []*indexingPressureMetric
 	threadPoolMetrics         []*threadPoolMetric
+	scriptContextMetrics      []*scriptContextMetric
+	tcpMetrics                []*tcpMetric
+	cgroupMetrics             []*cgroupMetric
 	filesystemDataMetrics     []*filesystemDataMetric
 	filesystemIODeviceMetrics []*filesystemIODeviceMetric
 }
 
 // NewNodes defines Nodes Prometheus metrics
-func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool, node string) *Nodes {
-	return &Nodes{
+func NewNodes(logger *slog.Logger, url *url.URL, client *http.Client) (Collector, error) {
+	nodes, err := config.ParseNodeSelector(esNodes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid es.node: %w", err)
+	}
+	metrics, err := parseNodeMetricGroups(esNodesMetrics)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nodes.metrics: %w", err)
+	}
+
+	c := &Nodes{
 		logger: logger,
 		client: client,
 		url:    url,
-		all:    all,
-		node:   node,
+		all:    esAllNodes,
+		nodes:  nodes,
+
+		sniff:         esSniff,
+		sniffParallel: esSniffParallel,
+		sniffTimeout:  esSniffTimeout,
+		metrics:       metrics,
+
+		transportActions: esNodesActions,
+
+		adaptiveSelection:           esAdaptiveSelection,
+		adaptiveSelectionMaxTargets: esAdaptiveSelectionMaxTargets,
 
 		nodeMetrics: []*nodeMetric{
 			{
-				Type: prometheus.GaugeValue,
+				Group: "os",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "os", "load1"),
 					"Shortterm load average",
@@ -211,7 +423,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "os",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "os", "load5"),
 					"Midterm load average",
@@ -223,7 +436,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "os",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "os", "load15"),
 					"Longterm load average",
@@ -235,7 +449,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "os",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "os", "cpu_percent"),
 					"Percent CPU used by OS",
@@ -247,7 +462,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "os",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "os", "mem_free_bytes"),
 					"Amount of free physical memory in bytes",
@@ -259,7 +475,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "os",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "os", "mem_used_bytes"),
 					"Amount of used physical memory in bytes",
@@ -271,7 +488,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "os",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "os", "mem_actual_free_bytes"),
 					"Amount of free physical memory in bytes",
@@ -283,7 +501,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "os",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "os", "mem_actual_used_bytes"),
 					"Amount of used physical memory in bytes",
@@ -295,7 +514,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "fielddata_memory_size_bytes"),
 					"Field data cache memory usage in bytes",
@@ -307,7 +527,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "fielddata_evictions"),
 					"Evictions from field data",
@@ -319,7 +540,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "completion_size_in_bytes"),
 					"Completion in bytes",
@@ -331,7 +553,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "filter_cache_memory_size_bytes"),
 					"Filter cache memory usage in bytes",
@@ -343,7 +566,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "filter_cache_evictions"),
 					"Evictions from filter cache",
@@ -355,7 +579,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "query_cache_memory_size_bytes"),
 					"Query cache memory usage in bytes",
@@ -367,7 +592,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "query_cache_evictions"),
 					"Evictions from query cache",
@@ -379,7 +605,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "query_cache_total"),
 					"Query cache total count",
@@ -391,7 +618,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "query_cache_cache_size"),
 					"Query cache cache size",
@@ -403,7 +631,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "query_cache_cache_total"),
 					"Query cache cache count",
@@ -415,7 +644,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "query_cache_count"),
 					"Query cache count",
@@ -427,7 +657,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultCacheHitLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "query_miss_count"),
 					"Query miss count",
@@ -439,7 +670,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultCacheMissLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "request_cache_memory_size_bytes"),
 					"Request cache memory usage in bytes",
@@ -451,7 +683,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "request_cache_evictions"),
 					"Evictions from request cache",
@@ -463,7 +696,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "request_cache_count"),
 					"Request cache count",
@@ -475,7 +709,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultCacheHitLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "request_miss_count"),
 					"Request miss count",
@@ -487,7 +722,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultCacheMissLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "translog_operations"),
 					"Total translog operations",
@@ -499,7 +735,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "translog_size_in_bytes"),
 					"Total translog size in bytes",
@@ -511,7 +748,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "get_time_seconds"),
 					"Total get time in seconds",
@@ -523,7 +761,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "get_total"),
 					"Total get",
@@ -535,7 +774,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "get_missing_time_seconds"),
 					"Total time of get missing in seconds",
@@ -547,7 +787,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "get_missing_total"),
 					"Total get missing",
@@ -559,7 +800,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "get_exists_time_seconds"),
 					"Total time get exists in seconds",
@@ -571,7 +813,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "get_exists_total"),
 					"Total get exists operations",
@@ -583,7 +826,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_refresh", "time_seconds_total"),
 					"Total time spent refreshing in seconds",
@@ -595,7 +839,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_refresh", "total"),
 					"Total refreshes",
@@ -607,7 +852,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_refresh", "external_total"),
 					"Total external refreshes",
@@ -619,7 +865,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_refresh", "external_time_seconds_total"),
 					"Total time spent external refreshing in seconds",
@@ -631,7 +878,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "search_query_time_seconds"),
 					"Total search query time in seconds",
@@ -643,7 +891,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "search_query_total"),
 					"Total number of queries",
@@ -655,7 +904,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "search_fetch_time_seconds"),
 					"Total search fetch time in seconds",
@@ -667,7 +917,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "search_fetch_total"),
 					"Total number of fetches",
@@ -679,7 +930,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "search_suggest_total"),
 					"Total number of suggests",
@@ -691,7 +943,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "search_suggest_time_seconds"),
 					"Total suggest time in seconds",
@@ -703,7 +956,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "search_scroll_total"),
 					"Total number of scrolls",
@@ -715,7 +969,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "search_scroll_time_seconds"),
 					"Total scroll time in seconds",
@@ -727,7 +982,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "search_scroll_current"),
 					"Number of scroll operations currently running",
@@ -739,7 +995,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "docs"),
 					"Count of documents on this node",
@@ -751,7 +1008,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "docs_deleted"),
 					"Count of deleted documents on this node",
@@ -763,7 +1021,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "store_size_bytes"),
 					"Current size of stored index data in bytes",
@@ -775,7 +1034,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "store_throttle_time_seconds_total"),
 					"Throttle time for index store in seconds",
@@ -787,7 +1047,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_memory_bytes"),
 					"Current memory size of segments in bytes",
@@ -799,7 +1060,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_count"),
 					"Count of index segments on this node",
@@ -811,7 +1073,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_terms_memory_in_bytes"),
 					"Count of terms in memory for this node",
@@ -823,7 +1086,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_index_writer_memory_in_bytes"),
 					"Count of memory for index writer on this node",
@@ -835,7 +1099,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_norms_memory_in_bytes"),
 					"Count of memory used by norms",
@@ -847,7 +1112,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_stored_fields_memory_in_bytes"),
 					"Count of stored fields memory",
@@ -859,7 +1125,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_doc_values_memory_in_bytes"),
 					"Count of doc values memory",
@@ -871,7 +1138,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_fixed_bit_set_memory_in_bytes"),
 					"Count of fixed bit set",
@@ -883,7 +1151,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_term_vectors_memory_in_bytes"),
 					"Term vectors memory usage in bytes",
@@ -895,7 +1164,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_points_memory_in_bytes"),
 					"Point values memory usage in bytes",
@@ -907,7 +1177,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "segments_version_map_memory_in_bytes"),
 					"Version map memory usage in bytes",
@@ -919,7 +1190,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "flush_total"),
 					"Total flushes",
@@ -931,7 +1203,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "flush_time_seconds"),
 					"Cumulative flush time in seconds",
@@ -943,7 +1216,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "warmer_total"),
 					"Total warmer count",
@@ -955,7 +1229,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices", "warmer_time_seconds_total"),
 					"Total warmer time in seconds",
@@ -967,7 +1242,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_indexing", "index_time_seconds_total"),
 					"Cumulative index time in seconds",
@@ -979,7 +1255,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_indexing", "index_total"),
 					"Total index calls",
@@ -991,7 +1268,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_indexing", "delete_time_seconds_total"),
 					"Total time indexing delete in seconds",
@@ -1003,7 +1281,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_indexing", "delete_total"),
 					"Total indexing deletes",
@@ -1015,7 +1294,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_indexing", "is_throttled"),
 					"Indexing throttling",
@@ -1030,7 +1310,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_indexing", "throttle_time_seconds_total"),
 					"Cumulative indexing throttling time",
@@ -1042,7 +1323,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_merges", "total"),
 					"Total merges",
@@ -1054,7 +1336,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_merges", "current"),
 					"Current merges",
@@ -1066,7 +1349,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "indices",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_merges", "current_size_in_bytes"),
 					"Size of a current merges in bytes",
@@ -1078,7 +1362,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_merges", "docs_total"),
 					"Cumulative docs merged",
@@ -1090,7 +1375,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_merges", "total_size_bytes_total"),
 					"Total merge size in bytes",
@@ -1102,7 +1388,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_merges", "total_time_seconds_total"),
 					"Total time spent merging in seconds",
@@ -1114,7 +1401,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "indices",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "indices_merges", "total_throttled_time_seconds_total"),
 					"Total throttled time of merges in seconds",
@@ -1126,7 +1414,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory", "used_bytes"),
 					"JVM memory currently used by area",
@@ -1140,7 +1429,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory", "used_bytes"),
 					"JVM memory currently used by area",
@@ -1154,7 +1444,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory", "max_bytes"),
 					"JVM memory max",
@@ -1168,7 +1459,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory", "committed_bytes"),
 					"JVM memory currently committed by area",
@@ -1182,7 +1474,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory", "committed_bytes"),
 					"JVM memory currently committed by area",
@@ -1196,7 +1489,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "used_bytes"),
 					"JVM memory currently used by pool",
@@ -1210,7 +1504,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "max_bytes"),
 					"JVM memory max by pool",
@@ -1224,7 +1519,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "peak_used_bytes"),
 					"JVM memory peak used by pool",
@@ -1238,7 +1534,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "peak_max_bytes"),
 					"JVM memory peak max by pool",
@@ -1252,7 +1549,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "used_bytes"),
 					"JVM memory currently used by pool",
@@ -1266,7 +1564,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "max_bytes"),
 					"JVM memory max by pool",
@@ -1280,7 +1579,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "peak_used_bytes"),
 					"JVM memory peak used by pool",
@@ -1294,7 +1594,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "peak_max_bytes"),
 					"JVM memory peak max by pool",
@@ -1308,7 +1609,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "used_bytes"),
 					"JVM memory currently used by pool",
@@ -1322,7 +1624,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "max_bytes"),
 					"JVM memory max by pool",
@@ -1336,7 +1639,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "peak_used_bytes"),
 					"JVM memory peak used by pool",
@@ -1350,7 +1654,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_memory_pool", "peak_max_bytes"),
 					"JVM memory peak max by pool",
@@ -1364,7 +1669,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_buffer_pool", "used_bytes"),
 					"JVM buffer currently used",
@@ -1378,7 +1684,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm_buffer_pool", "used_bytes"),
 					"JVM buffer currently used",
@@ -1392,7 +1699,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "jvm", "uptime_seconds"),
 					"JVM process uptime in seconds",
@@ -1406,7 +1714,125 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "jvm", "threads"),
+					"Current number of live JVM threads",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					return float64(node.JVM.Threads.Count)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "jvm", "threads_peak"),
+					"Peak number of live JVM threads",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					return float64(node.JVM.Threads.PeakCount)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "jvm_classes", "loaded"),
+					"Number of classes currently loaded by the JVM",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					return float64(node.JVM.Classes.CurrentLoaded)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "jvm_classes", "loaded_total"),
+					"Total number of classes loaded since the JVM started",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					return float64(node.JVM.Classes.TotalLoaded)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "jvm",
+				Type:  prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "jvm_classes", "unloaded_total"),
+					"Total number of classes unloaded since the JVM started",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					return float64(node.JVM.Classes.TotalUnloaded)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "jvm",
+				Type:  prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "jvm_memory", "heap_used_percent"),
+					"Percentage of the maximum heap currently in use",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					return float64(node.JVM.Mem.HeapUsedPercent)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "script",
+				Type:  prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "script", "compilations_total"),
+					"Number of scripts compiled",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					return float64(node.Script.Compilations)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "script",
+				Type:  prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "script", "cache_evictions_total"),
+					"Number of scripts evicted from the script cache",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					return float64(node.Script.CacheEvictions)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "script",
+				Type:  prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "script", "compilation_limit_triggered_total"),
+					"Number of times script compilation was rejected by the compilation rate limit",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					return float64(node.Script.CompilationLimitTriggered)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "process",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "process", "cpu_percent"),
 					"Percent CPU used by process",
@@ -1418,7 +1844,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "process",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "process", "mem_resident_size_bytes"),
 					"Resident memory in use by process in bytes",
@@ -1430,7 +1857,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "process",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "process", "mem_share_size_bytes"),
 					"Shared memory in use by process in bytes",
@@ -1442,7 +1870,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "process",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "process", "mem_virtual_size_bytes"),
 					"Total virtual memory used in bytes",
@@ -1454,7 +1883,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "process",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "process", "open_files_count"),
 					"Open file descriptors",
@@ -1466,7 +1896,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Group: "process",
+				Type:  prometheus.GaugeValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "process", "max_files_descriptors"),
 					"Max file descriptors",
@@ -1478,7 +1909,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "process",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "process", "cpu_seconds_total"),
 					"Process CPU time in seconds",
@@ -1492,7 +1924,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "transport",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "transport", "rx_packets_total"),
 					"Count of packets received",
@@ -1504,7 +1937,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "transport",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "transport", "rx_size_bytes_total"),
 					"Total number of bytes received",
@@ -1516,7 +1950,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "transport",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "transport", "tx_packets_total"),
 					"Count of packets sent",
@@ -1528,7 +1963,8 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultNodeLabelValues,
 			},
 			{
-				Type: prometheus.CounterValue,
+				Group: "transport",
+				Type:  prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, "transport", "tx_size_bytes_total"),
 					"Total number of bytes sent",
@@ -1539,6 +1975,38 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				},
 				Labels: defaultNodeLabelValues,
 			},
+			{
+				Group: "http",
+				Type:  prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "http", "current_open"),
+					"Current number of open HTTP connections",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					if node.HTTP == nil {
+						return 0
+					}
+					return float64(node.HTTP.CurrentOpen)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Group: "http",
+				Type:  prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "http", "opened_total"),
+					"Total number of opened HTTP connections",
+					defaultNodeLabels, nil,
+				),
+				Value: func(node NodeStatsNodeResponse) float64 {
+					if node.HTTP == nil {
+						return 0
+					}
+					return float64(node.HTTP.TotalOpened)
+				},
+				Labels: defaultNodeLabelValues,
+			},
 		},
 		gcCollectionMetrics: []*gcCollectionMetric{
 			{
@@ -1742,23 +2210,290 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 				Labels: defaultThreadPoolLabelValues,
 			},
 		},
-		filesystemDataMetrics: []*filesystemDataMetric{
+		scriptContextMetrics: []*scriptContextMetric{
 			{
-				Type: prometheus.GaugeValue,
+				Type: prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "filesystem_data", "available_bytes"),
-					"Available space on block device in bytes",
-					defaultFilesystemDataLabels, nil,
+					prometheus.BuildFQName(namespace, "script_context", "compilations_total"),
+					"Number of scripts compiled in the script context",
+					defaultScriptContextLabels, nil,
 				),
-				Value: func(fsStats NodeStatsFSDataResponse) float64 {
-					return float64(fsStats.Available)
+				Value: func(contextStats NodeStatsScriptContextResponse) float64 {
+					return float64(contextStats.Compilations)
+				},
+				Labels: func(cluster string, node NodeStatsNodeResponse, context string) []string {
+					return append(defaultNodeLabelValues(cluster, node), context)
 				},
-				Labels: defaultFilesystemDataLabelValues,
 			},
 			{
-				Type: prometheus.GaugeValue,
+				Type: prometheus.CounterValue,
 				Desc: prometheus.NewDesc(
-					prometheus.BuildFQName(namespace, "filesystem_data", "free_bytes"),
+					prometheus.BuildFQName(namespace, "script_context", "cache_evictions_total"),
+					"Number of scripts of the script context evicted from the script cache",
+					defaultScriptContextLabels, nil,
+				),
+				Value: func(contextStats NodeStatsScriptContextResponse) float64 {
+					return float64(contextStats.CacheEvictions)
+				},
+				Labels: func(cluster string, node NodeStatsNodeResponse, context string) []string {
+					return append(defaultNodeLabelValues(cluster, node), context)
+				},
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "script_context", "compilation_limit_triggered_total"),
+					"Number of times script compilation in the script context was rejected by the compilation rate limit",
+					defaultScriptContextLabels, nil,
+				),
+				Value: func(contextStats NodeStatsScriptContextResponse) float64 {
+					return float64(contextStats.CompilationLimitTriggered)
+				},
+				Labels: func(cluster string, node NodeStatsNodeResponse, context string) []string {
+					return append(defaultNodeLabelValues(cluster, node), context)
+				},
+			},
+		},
+		tcpMetrics: []*tcpMetric{
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "active_opens_total"),
+					"Number of TCP connections opened by the node",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.ActiveOpens)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "passive_opens_total"),
+					"Number of TCP connections accepted by the node",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.PassiveOpens)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "established"),
+					"Number of currently established TCP connections",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.CurrEstab)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "in_segments_total"),
+					"Number of TCP segments received",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.InSegs)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "out_segments_total"),
+					"Number of TCP segments sent",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.OutSegs)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "retransmitted_segments_total"),
+					"Number of TCP segments retransmitted",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.RetransSegs)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "established_resets_total"),
+					"Number of established TCP connections reset",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.EstabResets)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "attempt_fails_total"),
+					"Number of failed TCP connection attempts",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.AttemptFails)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "in_errors_total"),
+					"Number of TCP segments received in error",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.InErrs)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "network_tcp", "out_resets_total"),
+					"Number of TCP segments sent with the RST flag",
+					defaultNodeLabels, nil,
+				),
+				Value: func(tcpStats NodeStatsTCPResponse) float64 {
+					return float64(tcpStats.OutRsts)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+		},
+		cgroupMetrics: []*cgroupMetric{
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "os_cgroup", "cpuacct_usage_seconds_total"),
+					"CPU time consumed by all tasks in the control group of the node",
+					defaultNodeLabels, nil,
+				),
+				Value: func(cgroupStats NodeStatsOSCgroupResponse) (float64, bool) {
+					return float64(cgroupStats.CPUAcct.UsageNanos) / 1e9, true
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "os_cgroup", "cpu_cfs_period_seconds"),
+					"Period of the CFS bandwidth control of the control group of the node",
+					defaultNodeLabels, nil,
+				),
+				Value: func(cgroupStats NodeStatsOSCgroupResponse) (float64, bool) {
+					return float64(cgroupStats.CPU.CFSPeriodMicros) / 1e6, true
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "os_cgroup", "cpu_cfs_quota_seconds"),
+					"CPU time the control group of the node may use per CFS period, -1 if unlimited",
+					defaultNodeLabels, nil,
+				),
+				Value: func(cgroupStats NodeStatsOSCgroupResponse) (float64, bool) {
+					if cgroupStats.CPU.CFSQuotaMicros < 0 {
+						return -1, true
+					}
+					return float64(cgroupStats.CPU.CFSQuotaMicros) / 1e6, true
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "os_cgroup", "cpu_elapsed_periods_total"),
+					"Number of CFS periods elapsed for the control group of the node",
+					defaultNodeLabels, nil,
+				),
+				Value: func(cgroupStats NodeStatsOSCgroupResponse) (float64, bool) {
+					return float64(cgroupStats.CPU.Stat.ElapsedPeriods), true
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "os_cgroup", "cpu_throttled_periods_total"),
+					"Number of CFS periods in which the control group of the node was throttled",
+					defaultNodeLabels, nil,
+				),
+				Value: func(cgroupStats NodeStatsOSCgroupResponse) (float64, bool) {
+					return float64(cgroupStats.CPU.Stat.TimesThrottled), true
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.CounterValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "os_cgroup", "cpu_throttled_seconds_total"),
+					"Time the control group of the node was throttled for",
+					defaultNodeLabels, nil,
+				),
+				Value: func(cgroupStats NodeStatsOSCgroupResponse) (float64, bool) {
+					return float64(cgroupStats.CPU.Stat.TimeThrottledNanos) / 1e9, true
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "os_cgroup", "memory_limit_bytes"),
+					"Memory limit of the control group of the node, +Inf if unlimited",
+					defaultNodeLabels, nil,
+				),
+				Value: func(cgroupStats NodeStatsOSCgroupResponse) (float64, bool) {
+					return parseCgroupBytes(cgroupStats.Memory.LimitInBytes)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "os_cgroup", "memory_usage_bytes"),
+					"Memory used by the control group of the node",
+					defaultNodeLabels, nil,
+				),
+				Value: func(cgroupStats NodeStatsOSCgroupResponse) (float64, bool) {
+					return parseCgroupBytes(cgroupStats.Memory.UsageInBytes)
+				},
+				Labels: defaultNodeLabelValues,
+			},
+		},
+		filesystemDataMetrics: []*filesystemDataMetric{
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "filesystem_data", "available_bytes"),
+					"Available space on block device in bytes",
+					defaultFilesystemDataLabels, nil,
+				),
+				Value: func(fsStats NodeStatsFSDataResponse) float64 {
+					return float64(fsStats.Available)
+				},
+				Labels: defaultFilesystemDataLabelValues,
+			},
+			{
+				Type: prometheus.GaugeValue,
+				Desc: prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, "filesystem_data", "free_bytes"),
 					"Free space on block device in bytes",
 					defaultFilesystemDataLabels, nil,
 				),
@@ -1843,193 +2578,541 @@ func NewNodes(logger *slog.Logger, client *http.Client, url *url.URL, all bool,
 			},
 		},
 	}
+	if len(metrics) > 0 {
+		c.nodeMetrics = slices.DeleteFunc(c.nodeMetrics, func(m *nodeMetric) bool {
+			return !slices.Contains(metrics, m.Group)
+		})
+		if !slices.Contains(metrics, "jvm") {
+			c.gcCollectionMetrics = nil
+		}
+		if !slices.Contains(metrics, "breaker") {
+			c.breakerMetrics = nil
+		}
+		if !slices.Contains(metrics, "indexing_pressure") {
+			c.indexingPressureMetrics = nil
+		}
+		if !slices.Contains(metrics, "thread_pool") {
+			c.threadPoolMetrics = nil
+		}
+		if !slices.Contains(metrics, "os") {
+			c.cgroupMetrics = nil
+		}
+		if !slices.Contains(metrics, "script") {
+			c.scriptContextMetrics = nil
+		}
+		if !slices.Contains(metrics, "fs") {
+			c.filesystemDataMetrics = nil
+			c.filesystemIODeviceMetrics = nil
+		}
+	}
+	return c, nil
 }
 
-// Describe add metrics descriptions
-func (c *Nodes) Describe(ch chan<- *prometheus.Desc) {
-	ch <- nodesRolesMetric
-
-	for _, metric := range c.nodeMetrics {
-		ch <- metric.Desc
-	}
-	for _, metric := range c.gcCollectionMetrics {
-		ch <- metric.Desc
-	}
-	for _, metric := range c.breakerMetrics {
-		ch <- metric.Desc
-	}
-	for _, metric := range c.indexingPressureMetrics {
-		ch <- metric.Desc
+// parseNodeMetricGroups splits comma separated metric groups and validates
+// them against nodeMetricGroups.
+func parseNodeMetricGroups(groups []string) ([]string, error) {
+	var metrics []string
+	for _, g := range groups {
+		for group := range strings.SplitSeq(g, ",") {
+			group = strings.TrimSpace(group)
+			if _, ok := nodeMetricGroups[group]; !ok {
+				return nil, fmt.Errorf("unknown metric group %q", group)
+			}
+			if !slices.Contains(metrics, group) {
+				metrics = append(metrics, group)
+			}
+		}
 	}
-	for _, metric := range c.threadPoolMetrics {
-		ch <- metric.Desc
+	return metrics, nil
+}
+
+// groupEnabled reports whether the given metric group is queried.
+func (c *Nodes) groupEnabled(group string) bool {
+	return len(c.metrics) == 0 || slices.Contains(c.metrics, group)
+}
+
+// nodeStatsURL returns the nodes stats URL on u for the given node selector,
+// limited to the configured metric groups. The http section is always
+// requested, as the client role of a node is derived from its presence.
+// version is the version of the cluster, extra metrics are only requested if
+// it supports them.
+func (c *Nodes) nodeStatsURL(u url.URL, selector string, version semver.Version) string {
+	u.Path = path.Join(u.Path, "_nodes", selector, "stats")
+	if len(c.metrics) == 0 {
+		return u.String()
 	}
-	for _, metric := range c.filesystemDataMetrics {
-		ch <- metric.Desc
+
+	metrics := slices.Clone(c.metrics)
+	filterPath := []string{"cluster_name", "nodes.*.name", "nodes.*.host", "nodes.*.timestamp", "nodes.*.transport_address", "nodes.*.roles", "nodes.*.attributes"}
+	for _, group := range c.metrics {
+		filterPath = append(filterPath, "nodes.*."+nodeMetricGroups[group])
+		for _, extra := range nodeMetricGroupExtras[group] {
+			if version.LT(extra.since) || version.GTE(extra.until) {
+				continue
+			}
+			metrics = append(metrics, extra.metric)
+			filterPath = append(filterPath, "nodes.*."+extra.metric)
+		}
 	}
-	for _, metric := range c.filesystemIODeviceMetrics {
-		ch <- metric.Desc
+	if !c.groupEnabled("http") {
+		metrics = append(metrics, "http")
+		filterPath = append(filterPath, "nodes.*.http.current_open")
 	}
+	u.Path = path.Join(u.Path, strings.Join(metrics, ","))
+	q := u.Query()
+	q.Set("filter_path", strings.Join(filterPath, ","))
+	u.RawQuery = q.Encode()
+	return u.String()
 }
 
-func (c *Nodes) fetchAndDecodeNodeStats() (nodeStatsResponse, error) {
-	var nsr nodeStatsResponse
-
-	u := *c.url
+// configure implements targetConfigurer.
+func (c *Nodes) configure(s targetSettings) {
+	if len(s.nodes) > 0 {
+		c.all = false
+		c.sniff = false
+		c.nodes = s.nodes
+	}
+}
 
+// streamAndEmitNodeStats GETs the nodes stats and emits the per-node metrics
+// while decoding the response one node at a time, so that memory use does not
+// grow with the number of nodes in the cluster.
+func (c *Nodes) streamAndEmitNodeStats(ctx context.Context, ch chan<- prometheus.Metric, version semver.Version) error {
+	selector := strings.Join(c.nodes, ",")
 	if c.all {
-		u.Path = path.Join(u.Path, "/_nodes/stats")
-	} else {
-		u.Path = path.Join(u.Path, "_nodes", c.node, "stats")
+		selector = ""
 	}
 
-	res, err := c.client.Get(u.String())
+	var (
+		clusterName string
+		resolved    int
+	)
+	err := fetchURL(ctx, c.client, c.logger, c.nodeStatsURL(*c.url, selector, version), func(r io.Reader) error {
+		var err error
+		clusterName, err = streamNodeStats(r, func(clusterName, nodeID string, node NodeStatsNodeResponse) {
+			resolved++
+			c.emitNodeMetrics(ch, clusterName, nodeID, node)
+		})
+		return err
+	})
 	if err != nil {
-		return nsr, fmt.Errorf("failed to get cluster health from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
+		return err
 	}
 
-	defer func() {
-		err = res.Body.Close()
+	ch <- prometheus.MustNewConstMetric(
+		nodesResolvedMetric,
+		prometheus.GaugeValue,
+		float64(resolved),
+		clusterName,
+	)
+	return nil
+}
+
+// streamNodeStats decodes a nodes stats JSON response one node at a time,
+// invoking emit for each, so the stats of all nodes are never held in memory
+// at once. It returns the cluster name, which Elasticsearch sends ahead of the
+// nodes; _nodes is consumed and discarded.
+func streamNodeStats(r io.Reader, emit func(clusterName, nodeID string, node NodeStatsNodeResponse)) (string, error) {
+	dec := json.NewDecoder(r)
+
+	var clusterName string
+	if _, err := dec.Token(); err != nil { // opening '{' of the response
+		return clusterName, err
+	}
+	for dec.More() {
+		keyTok, err := dec.Token()
 		if err != nil {
-			c.logger.Warn(
-				"failed to close http.Client",
-				"err", err,
-			)
+			return clusterName, err
+		}
+		key, _ := keyTok.(string)
+		if key == "cluster_name" {
+			if err := dec.Decode(&clusterName); err != nil {
+				return clusterName, err
+			}
+			continue
+		}
+		if key != "nodes" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return clusterName, err
+			}
+			continue
 		}
-	}()
 
-	if res.StatusCode != http.StatusOK {
-		return nsr, fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
+		if _, err := dec.Token(); err != nil { // opening '{' of nodes
+			return clusterName, err
+		}
+		for dec.More() {
+			idTok, err := dec.Token()
+			if err != nil {
+				return clusterName, err
+			}
+			nodeID, _ := idTok.(string)
+
+			var node NodeStatsNodeResponse
+			if err := dec.Decode(&node); err != nil {
+				return clusterName, err
+			}
+			emit(clusterName, nodeID, node)
+		}
+		if _, err := dec.Token(); err != nil { // closing '}' of nodes
+			return clusterName, err
+		}
 	}
+	return clusterName, nil
+}
 
-	bts, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nsr, err
+// Update gets nodes metric values
+func (c *Nodes) Update(ctx context.Context, uc UpdateContext, ch chan<- prometheus.Metric) error {
+	version := c.clusterVersion(ctx, uc)
+	if c.sniff {
+		return c.updateSniffed(ctx, ch, version)
 	}
 
-	if err := json.Unmarshal(bts, &nsr); err != nil {
-		return nsr, err
+	if err := c.streamAndEmitNodeStats(ctx, ch, version); err != nil {
+		return fmt.Errorf("failed to fetch and decode node stats: %w", err)
 	}
-	return nsr, nil
+	return nil
 }
 
-// Collect gets nodes metric values
-func (c *Nodes) Collect(ch chan<- prometheus.Metric) {
-	nodeStatsResp, err := c.fetchAndDecodeNodeStats()
+// clusterVersion returns the version of the cluster if metric groups are
+// selected, which may need extra metrics depending on the version. The version
+// is zero if it is not needed or unknown, so that no extra metrics are
+// requested.
+func (c *Nodes) clusterVersion(ctx context.Context, uc UpdateContext) semver.Version {
+	if len(c.metrics) == 0 {
+		return semver.Version{}
+	}
+	info, err := uc.GetClusterInfo(ctx)
 	if err != nil {
-		c.logger.Warn(
-			"failed to fetch and decode node stats",
-			"err", err,
+		c.logger.Debug("failed to get cluster version", "err", err)
+		return semver.Version{}
+	}
+	version, err := semver.ParseTolerant(info.Version.Number)
+	if err != nil {
+		return semver.Version{}
+	}
+	return version
+}
+
+// emitNodeMetrics writes all metrics of a single node to ch.
+func (c *Nodes) emitNodeMetrics(ch chan<- prometheus.Metric, clusterName, nodeID string, node NodeStatsNodeResponse) {
+	// Handle the node labels metric
+	roles := getRoles(node)
+
+	for role, roleEnabled := range roles {
+		val := 0.0
+		if roleEnabled {
+			val = 1.0
+		}
+
+		labels := []string{
+			clusterName,
+			node.Host,
+			node.Name,
+			nodeID,
+			role,
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			nodesRolesMetric,
+			prometheus.GaugeValue,
+			val,
+			labels...,
 		)
-		return
 	}
 
-	for nodeID, node := range nodeStatsResp.Nodes {
-		// Handle the node labels metric
-		roles := getRoles(node)
+	for _, metric := range c.nodeMetrics {
+		ch <- prometheus.MustNewConstMetric(
+			metric.Desc,
+			metric.Type,
+			metric.Value(node),
+			metric.Labels(clusterName, node)...,
+		)
+	}
 
-		for role, roleEnabled := range roles {
-			val := 0.0
-			if roleEnabled {
-				val = 1.0
-			}
+	// Memory pressure is only exported for nodes that report an old
+	// generation pool with a maximum size, which depends on the garbage
+	// collector of the JVM.
+	if old, ok := node.JVM.Mem.Pools["old"]; ok && old.Max > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			jvmMemoryPressureMetric,
+			prometheus.GaugeValue,
+			100*float64(old.Used)/float64(old.Max),
+			defaultNodeLabelValues(clusterName, node)...,
+		)
+	}
 
-			labels := []string{
-				nodeStatsResp.ClusterName,
-				node.Host,
-				node.Name,
-				nodeID,
-				role,
-			}
+	// GC Stats
+	for collector, gcStats := range node.JVM.GC.Collectors {
+		for _, metric := range c.gcCollectionMetrics {
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				metric.Value(gcStats),
+				metric.Labels(clusterName, node, collector)...,
+			)
+		}
+	}
 
+	// Breaker stats
+	for breaker, bstats := range node.Breakers {
+		for _, metric := range c.breakerMetrics {
 			ch <- prometheus.MustNewConstMetric(
-				nodesRolesMetric,
-				prometheus.GaugeValue,
-				val,
-				labels...,
+				metric.Desc,
+				metric.Type,
+				metric.Value(bstats),
+				metric.Labels(clusterName, node, breaker)...,
 			)
 		}
+	}
 
-		for _, metric := range c.nodeMetrics {
+	// Indexing Pressure stats
+	for indexingPressure, ipstats := range node.IndexingPressure {
+		for _, metric := range c.indexingPressureMetrics {
 			ch <- prometheus.MustNewConstMetric(
 				metric.Desc,
 				metric.Type,
-				metric.Value(node),
-				metric.Labels(nodeStatsResp.ClusterName, node)...,
+				metric.Value(ipstats),
+				metric.Labels(clusterName, node, indexingPressure)...,
 			)
 		}
+	}
 
-		// GC Stats
-		for collector, gcStats := range node.JVM.GC.Collectors {
-			for _, metric := range c.gcCollectionMetrics {
-				ch <- prometheus.MustNewConstMetric(
-					metric.Desc,
-					metric.Type,
-					metric.Value(gcStats),
-					metric.Labels(nodeStatsResp.ClusterName, node, collector)...,
-				)
-			}
+	// Thread Pool stats
+	for pool, pstats := range node.ThreadPool {
+		for _, metric := range c.threadPoolMetrics {
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				metric.Value(pstats),
+				metric.Labels(clusterName, node, pool)...,
+			)
 		}
+	}
 
-		// Breaker stats
-		for breaker, bstats := range node.Breakers {
-			for _, metric := range c.breakerMetrics {
-				ch <- prometheus.MustNewConstMetric(
-					metric.Desc,
-					metric.Type,
-					metric.Value(bstats),
-					metric.Labels(nodeStatsResp.ClusterName, node, breaker)...,
-				)
+	// Script context stats
+	scriptContexts := node.Script.Contexts
+	if len(scriptContexts) == 0 && node.ScriptCache != nil {
+		scriptContexts = node.ScriptCache.Contexts
+	}
+	for _, contextStats := range scriptContexts {
+		for _, metric := range c.scriptContextMetrics {
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				metric.Value(contextStats),
+				metric.Labels(clusterName, node, contextStats.Context)...,
+			)
+		}
+	}
+
+	// Discovery stats
+	if c.groupEnabled("discovery") {
+		emitDiscoveryMetrics(ch, defaultNodeLabelValues(clusterName, node), node.Discovery)
+	}
+
+	// Adaptive replica selection stats
+	if c.adaptiveSelection && c.groupEnabled("adaptive_selection") {
+		emitAdaptiveSelectionMetrics(ch, []string{clusterName, node.Host, node.Name, nodeID}, node.AdaptiveSelection, c.adaptiveSelectionMaxTargets)
+	}
+
+	// TCP stats
+	if node.Network != nil {
+		for _, metric := range c.tcpMetrics {
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				metric.Value(node.Network.TCP),
+				metric.Labels(clusterName, node)...,
+			)
+		}
+	}
+
+	// Control group stats
+	if node.OS.Cgroup != nil {
+		for _, metric := range c.cgroupMetrics {
+			value, ok := metric.Value(*node.OS.Cgroup)
+			if !ok {
+				continue
 			}
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				value,
+				metric.Labels(clusterName, node)...,
+			)
 		}
+	}
 
-		// Indexing Pressure stats
-		for indexingPressure, ipstats := range node.IndexingPressure {
-			for _, metric := range c.indexingPressureMetrics {
-				ch <- prometheus.MustNewConstMetric(
-					metric.Desc,
-					metric.Type,
-					metric.Value(ipstats),
-					metric.Labels(nodeStatsResp.ClusterName, node, indexingPressure)...,
-				)
+	// File System Data Stats
+	for _, fsDataStats := range node.FS.Data {
+		for _, metric := range c.filesystemDataMetrics {
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				metric.Value(fsDataStats),
+				metric.Labels(clusterName, node, fsDataStats.Mount, fsDataStats.Path)...,
+			)
+		}
+	}
+
+	// HTTP client and route stats
+	if node.HTTP != nil && c.groupEnabled("http") {
+		// Individual clients are not exported, their ids and addresses change
+		// with every connection.
+		if node.HTTP.Clients != nil {
+			var clients, requests [2]float64
+			for _, client := range node.HTTP.Clients {
+				state := 0
+				if client.ClosedTime > 0 {
+					state = 1
+				}
+				clients[state]++
+				requests[state] += float64(client.RequestCount)
+			}
+			for i, state := range []string{"open", "closed"} {
+				labels := append(defaultNodeLabelValues(clusterName, node), state)
+				ch <- prometheus.MustNewConstMetric(httpClientsMetric, prometheus.GaugeValue, clients[i], labels...)
+				ch <- prometheus.MustNewConstMetric(httpClientRequestsMetric, prometheus.GaugeValue, requests[i], labels...)
 			}
 		}
+		for route, stats := range node.HTTP.Routes {
+			labels := append(defaultNodeLabelValues(clusterName, node), route)
+			ch <- prometheus.MustNewConstHistogram(
+				httpRouteRequestSizeMetric,
+				uint64(stats.Requests.Count),
+				float64(stats.Requests.TotalSize),
+				sizeHistogramBuckets(stats.Requests.SizeHistogram),
+				labels...,
+			)
+			ch <- prometheus.MustNewConstHistogram(
+				httpRouteResponseSizeMetric,
+				uint64(stats.Responses.Count),
+				float64(stats.Responses.TotalSize),
+				sizeHistogramBuckets(stats.Responses.SizeHistogram),
+				labels...,
+			)
+		}
+	}
 
-		// Thread Pool stats
-		for pool, pstats := range node.ThreadPool {
-			for _, metric := range c.threadPoolMetrics {
-				ch <- prometheus.MustNewConstMetric(
-					metric.Desc,
-					metric.Type,
-					metric.Value(pstats),
-					metric.Labels(nodeStatsResp.ClusterName, node, pool)...,
+	// Transport handling time and action stats
+	if c.groupEnabled("transport") {
+		labels := defaultNodeLabelValues(clusterName, node)
+		if hist := node.Transport.InboundHandlingTimeHistogram; len(hist) > 0 {
+			emitTimeHistogramBuckets(ch, transportInboundHandlingTimeMetric, hist, labels)
+		}
+		if hist := node.Transport.OutboundHandlingTimeHistogram; len(hist) > 0 {
+			emitTimeHistogramBuckets(ch, transportOutboundHandlingTimeMetric, hist, labels)
+		}
+		if c.transportActions {
+			for action, stats := range node.Transport.Actions {
+				actionLabels := append(labels[:len(labels):len(labels)], action)
+				ch <- prometheus.MustNewConstHistogram(
+					transportActionRequestSizeMetric,
+					uint64(stats.Requests.Count),
+					float64(stats.Requests.TotalSize),
+					sizeHistogramBuckets(stats.Requests.SizeHistogram),
+					actionLabels...,
+				)
+				ch <- prometheus.MustNewConstHistogram(
+					transportActionResponseSizeMetric,
+					uint64(stats.Responses.Count),
+					float64(stats.Responses.TotalSize),
+					sizeHistogramBuckets(stats.Responses.SizeHistogram),
+					actionLabels...,
 				)
 			}
 		}
+	}
 
-		// File System Data Stats
-		for _, fsDataStats := range node.FS.Data {
-			for _, metric := range c.filesystemDataMetrics {
-				ch <- prometheus.MustNewConstMetric(
-					metric.Desc,
-					metric.Type,
-					metric.Value(fsDataStats),
-					metric.Labels(nodeStatsResp.ClusterName, node, fsDataStats.Mount, fsDataStats.Path)...,
-				)
+	// File System IO Device Stats
+	for _, fsIODeviceStats := range node.FS.IOStats.Devices {
+		for _, metric := range c.filesystemIODeviceMetrics {
+			ch <- prometheus.MustNewConstMetric(
+				metric.Desc,
+				metric.Type,
+				metric.Value(fsIODeviceStats),
+				metric.Labels(clusterName, node, fsIODeviceStats.DeviceName)...,
+			)
+		}
+	}
+}
+
+// Elasticsearch tracks sizes and handling times in fixed power-of-two buckets
+// with these upper bounds, followed by one unbounded bucket: 1 byte to 64MiB
+// for HTTP and transport sizes, 1ms to 65.536s for transport handling times.
+const (
+	sizeHistogramBucketCount = 27
+	timeHistogramBucketCount = 17
+)
+
+// sizeHistogramBuckets converts the buckets of an Elasticsearch size histogram
+// into the cumulative buckets of a Prometheus histogram, keyed by their upper
+// bound. Elasticsearch only reports non-empty buckets, so every bucket of the
+// fixed layout is emitted with the cumulative count of the buckets below it to
+// keep the series stable between scrapes. The unbounded bucket is covered by
+// the implicit +Inf bucket.
+func sizeHistogramBuckets(hist []NodeStatsSizeHistogramBucketResponse) map[float64]uint64 {
+	buckets := make(map[float64]uint64, sizeHistogramBucketCount)
+	for i := 0; i < sizeHistogramBucketCount; i++ {
+		bound := int64(1) << i
+		var cumulative uint64
+		for _, b := range hist {
+			if b.LtBytes > 0 && b.LtBytes <= bound {
+				cumulative += uint64(b.Count)
 			}
 		}
+		buckets[float64(bound)] = cumulative
+	}
+	return buckets
+}
 
-		// File System IO Device Stats
-		for _, fsIODeviceStats := range node.FS.IOStats.Devices {
-			for _, metric := range c.filesystemIODeviceMetrics {
-				ch <- prometheus.MustNewConstMetric(
-					metric.Desc,
-					metric.Type,
-					metric.Value(fsIODeviceStats),
-					metric.Labels(nodeStatsResp.ClusterName, node, fsIODeviceStats.DeviceName)...,
-				)
+// timeHistogramBuckets converts an Elasticsearch time histogram to the total
+// count and the cumulative buckets in seconds, using the same fixed layout as
+// sizeHistogramBuckets.
+func timeHistogramBuckets(hist []NodeStatsTimeHistogramBucketResponse) (uint64, map[float64]uint64) {
+	var count uint64
+	for _, b := range hist {
+		count += uint64(b.Count)
+	}
+
+	buckets := make(map[float64]uint64, timeHistogramBucketCount)
+	for i := 0; i < timeHistogramBucketCount; i++ {
+		bound := int64(1) << i
+		var cumulative uint64
+		for _, b := range hist {
+			if b.LtMillis > 0 && b.LtMillis <= bound {
+				cumulative += uint64(b.Count)
 			}
 		}
+		buckets[float64(bound)/1000] = cumulative
+	}
+	return count, buckets
+}
+
+// emitTimeHistogramBuckets sends the cumulative buckets of an Elasticsearch
+// time histogram as counters with an le label. Elasticsearch does not report
+// the total time, so they are not exported as a histogram with a made up sum.
+func emitTimeHistogramBuckets(ch chan<- prometheus.Metric, desc *prometheus.Desc, hist []NodeStatsTimeHistogramBucketResponse, labels []string) {
+	count, buckets := timeHistogramBuckets(hist)
+	for _, bound := range slices.Sorted(maps.Keys(buckets)) {
+		le := strconv.FormatFloat(bound, 'f', -1, 64)
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(buckets[bound]), append(labels[:len(labels):len(labels)], le)...)
+	}
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(count), append(labels[:len(labels):len(labels)], "+Inf")...)
+}
+
+// parseCgroupBytes parses a memory value of the control group stats, which
+// cgroups v2 reports as "max" when no limit is set. It returns false if the
+// value is empty or invalid.
+func parseCgroupBytes(s string) (float64, bool) {
+	if s == "max" {
+		return math.Inf(1), true
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
 	}
+	return v, true
 }
@@ -0,0 +1,88 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultAdaptiveSelectionLabels = append(defaultRoleLabels, "target_node")
+
+	adaptiveSelectionTargetsMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "adaptive_selection", "targets"),
+		"Number of nodes the node keeps adaptive replica selection stats about, including the ones not exported",
+		defaultRoleLabels, nil,
+	)
+	adaptiveSelectionOutgoingSearchesMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "adaptive_selection", "outgoing_searches"),
+		"Number of searches in flight from the node to the target node",
+		defaultAdaptiveSelectionLabels, nil,
+	)
+	adaptiveSelectionAvgQueueSizeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "adaptive_selection", "avg_queue_size"),
+		"Exponentially weighted moving average of the search queue size of the target node",
+		defaultAdaptiveSelectionLabels, nil,
+	)
+	adaptiveSelectionAvgServiceTimeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "adaptive_selection", "avg_service_time_seconds"),
+		"Exponentially weighted moving average of the time the target node took to execute searches",
+		defaultAdaptiveSelectionLabels, nil,
+	)
+	adaptiveSelectionAvgResponseTimeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "adaptive_selection", "avg_response_time_seconds"),
+		"Exponentially weighted moving average of the time searches sent to the target node took to respond",
+		defaultAdaptiveSelectionLabels, nil,
+	)
+	adaptiveSelectionRankMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "adaptive_selection", "rank"),
+		"Rank of the target node for replica selection, lower is preferred",
+		defaultAdaptiveSelectionLabels, nil,
+	)
+)
+
+// emitAdaptiveSelectionMetrics writes the adaptive replica selection stats a
+// node keeps about the nodes it sends searches to. As the stats form a matrix
+// of all pairs of nodes, only the maxTargets target nodes with the highest
+// average response time are exported if maxTargets is positive.
+func emitAdaptiveSelectionMetrics(ch chan<- prometheus.Metric, labels []string, stats map[string]NodeStatsAdaptiveSelectionResponse, maxTargets int) {
+	ch <- prometheus.MustNewConstMetric(adaptiveSelectionTargetsMetric, prometheus.GaugeValue, float64(len(stats)), labels...)
+
+	targets := slices.SortedFunc(maps.Keys(stats), func(a, b string) int {
+		return cmp.Or(
+			cmp.Compare(stats[b].AvgResponseTimeNs, stats[a].AvgResponseTimeNs),
+			cmp.Compare(a, b),
+		)
+	})
+	if maxTargets > 0 && len(targets) > maxTargets {
+		targets = targets[:maxTargets]
+	}
+
+	for _, target := range targets {
+		s := stats[target]
+		targetLabels := append(labels[:len(labels):len(labels)], target)
+		ch <- prometheus.MustNewConstMetric(adaptiveSelectionOutgoingSearchesMetric, prometheus.GaugeValue, float64(s.OutgoingSearches), targetLabels...)
+		ch <- prometheus.MustNewConstMetric(adaptiveSelectionAvgQueueSizeMetric, prometheus.GaugeValue, float64(s.AvgQueueSize), targetLabels...)
+		ch <- prometheus.MustNewConstMetric(adaptiveSelectionAvgServiceTimeMetric, prometheus.GaugeValue, float64(s.AvgServiceTimeNs)/1e9, targetLabels...)
+		ch <- prometheus.MustNewConstMetric(adaptiveSelectionAvgResponseTimeMetric, prometheus.GaugeValue, float64(s.AvgResponseTimeNs)/1e9, targetLabels...)
+		if rank, err := strconv.ParseFloat(s.Rank, 64); err == nil {
+			ch <- prometheus.MustNewConstMetric(adaptiveSelectionRankMetric, prometheus.GaugeValue, rank, targetLabels...)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestNodesAdaptiveSelection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{
+			"cluster_name": "elasticsearch",
+			"nodes": {
+				"n1": {
+					"name": "es-1",
+					"host": "10.0.0.1",
+					"roles": ["data"],
+					"adaptive_selection": {
+						"n1": {"outgoing_searches": 0, "avg_queue_size": 0, "avg_service_time_ns": 1000000, "avg_response_time_ns": 2000000, "rank": "2.0"},
+						"n2": {"outgoing_searches": 3, "avg_queue_size": 5, "avg_service_time_ns": 250000000, "avg_response_time_ns": 500000000, "rank": "500.0"},
+						"n3": {"outgoing_searches": 1, "avg_queue_size": 1, "avg_service_time_ns": 10000000, "avg_response_time_ns": 20000000, "rank": "20.0"}
+					}
+				}
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := c.(*Nodes)
+	n.adaptiveSelection = true
+	n.adaptiveSelectionMaxTargets = 2
+
+	want := `# HELP elasticsearch_adaptive_selection_targets Number of nodes the node keeps adaptive replica selection stats about, including the ones not exported
+# TYPE elasticsearch_adaptive_selection_targets gauge
+elasticsearch_adaptive_selection_targets{cluster="elasticsearch",host="10.0.0.1",name="es-1",node="n1"} 3
+# HELP elasticsearch_adaptive_selection_avg_response_time_seconds Exponentially weighted moving average of the time searches sent to the target node took to respond
+# TYPE elasticsearch_adaptive_selection_avg_response_time_seconds gauge
+elasticsearch_adaptive_selection_avg_response_time_seconds{cluster="elasticsearch",host="10.0.0.1",name="es-1",node="n1",target_node="n2"} 0.5
+elasticsearch_adaptive_selection_avg_response_time_seconds{cluster="elasticsearch",host="10.0.0.1",name="es-1",node="n1",target_node="n3"} 0.02
+# HELP elasticsearch_adaptive_selection_outgoing_searches Number of searches in flight from the node to the target node
+# TYPE elasticsearch_adaptive_selection_outgoing_searches gauge
+elasticsearch_adaptive_selection_outgoing_searches{cluster="elasticsearch",host="10.0.0.1",name="es-1",node="n1",target_node="n2"} 3
+elasticsearch_adaptive_selection_outgoing_searches{cluster="elasticsearch",host="10.0.0.1",name="es-1",node="n1",target_node="n3"} 1
+# HELP elasticsearch_adaptive_selection_rank Rank of the target node for replica selection, lower is preferred
+# TYPE elasticsearch_adaptive_selection_rank gauge
+elasticsearch_adaptive_selection_rank{cluster="elasticsearch",host="10.0.0.1",name="es-1",node="n1",target_node="n2"} 500
+elasticsearch_adaptive_selection_rank{cluster="elasticsearch",host="10.0.0.1",name="es-1",node="n1",target_node="n3"} 20
+`
+	if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(want),
+		"elasticsearch_adaptive_selection_targets", "elasticsearch_adaptive_selection_avg_response_time_seconds",
+		"elasticsearch_adaptive_selection_outgoing_searches", "elasticsearch_adaptive_selection_rank"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	discoveryClusterStateQueueMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "discovery", "cluster_state_queue"),
+		"Number of cluster states in the queue of the node by state",
+		append(defaultNodeLabels, "state"), nil,
+	)
+	discoveryPublishedClusterStatesMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "discovery", "published_cluster_states_total"),
+		"Number of cluster states published by the node by type",
+		append(defaultNodeLabels, "type"), nil,
+	)
+	discoveryClusterStateUpdatesMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "discovery", "cluster_state_updates_total"),
+		"Number of cluster state updates computed by the elected master by outcome",
+		append(defaultNodeLabels, "outcome"), nil,
+	)
+	discoveryClusterStateUpdateSecondsMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "discovery", "cluster_state_update_seconds_total"),
+		"Time spent by the elected master in the phases of cluster state updates by outcome",
+		append(defaultNodeLabels, "outcome", "phase"), nil,
+	)
+	discoverySerializedClusterStatesMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "discovery", "serialized_cluster_states_total"),
+		"Number of cluster states serialized by the node by type",
+		append(defaultNodeLabels, "type"), nil,
+	)
+	discoverySerializedClusterStatesUncompressedMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "discovery", "serialized_cluster_states_uncompressed_bytes_total"),
+		"Size of the cluster states serialized by the node before compression by type",
+		append(defaultNodeLabels, "type"), nil,
+	)
+	discoverySerializedClusterStatesCompressedMetric = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "discovery", "serialized_cluster_states_compressed_bytes_total"),
+		"Size of the cluster states serialized by the node after compression by type",
+		append(defaultNodeLabels, "type"), nil,
+	)
+)
+
+// emitDiscoveryMetrics writes the discovery stats of a node to ch. The
+// sections of the stats depend on the Elasticsearch version and are only
+// exported if the node reports them.
+func emitDiscoveryMetrics(ch chan<- prometheus.Metric, labels []string, d NodeStatsDiscoveryResponse) {
+	with := func(values ...string) []string {
+		return append(labels[:len(labels):len(labels)], values...)
+	}
+
+	if q := d.ClusterStateQueue; q != nil {
+		ch <- prometheus.MustNewConstMetric(discoveryClusterStateQueueMetric, prometheus.GaugeValue, float64(q.Pending), with("pending")...)
+		ch <- prometheus.MustNewConstMetric(discoveryClusterStateQueueMetric, prometheus.GaugeValue, float64(q.Committed), with("committed")...)
+	}
+
+	if p := d.PublishedClusterStates; p != nil {
+		ch <- prometheus.MustNewConstMetric(discoveryPublishedClusterStatesMetric, prometheus.CounterValue, float64(p.FullStates), with("full")...)
+		ch <- prometheus.MustNewConstMetric(discoveryPublishedClusterStatesMetric, prometheus.CounterValue, float64(p.CompatibleDiffs), with("compatible_diff")...)
+		ch <- prometheus.MustNewConstMetric(discoveryPublishedClusterStatesMetric, prometheus.CounterValue, float64(p.IncompatibleDiffs), with("incompatible_diff")...)
+	}
+
+	// Each outcome reports its count and the time spent in every phase of
+	// the update as <phase>_time_millis. The phases differ between the
+	// outcomes and Elasticsearch versions, so they are taken as reported.
+	for outcome, stats := range d.ClusterStateUpdate {
+		ch <- prometheus.MustNewConstMetric(discoveryClusterStateUpdatesMetric, prometheus.CounterValue, float64(stats["count"]), with(outcome)...)
+		for key, millis := range stats {
+			phase, ok := strings.CutSuffix(key, "_time_millis")
+			if !ok {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(discoveryClusterStateUpdateSecondsMetric, prometheus.CounterValue, float64(millis)/1000, with(outcome, phase)...)
+		}
+	}
+
+	if s := d.SerializedClusterStates; s != nil {
+		for typ, stats := range map[string]NodeStatsDiscoverySerializedResponse{"full": s.FullStates, "diff": s.Diffs} {
+			ch <- prometheus.MustNewConstMetric(discoverySerializedClusterStatesMetric, prometheus.CounterValue, float64(stats.Count), with(typ)...)
+			ch <- prometheus.MustNewConstMetric(discoverySerializedClusterStatesUncompressedMetric, prometheus.CounterValue, float64(stats.UncompressedSize), with(typ)...)
+			ch <- prometheus.MustNewConstMetric(discoverySerializedClusterStatesCompressedMetric, prometheus.CounterValue, float64(stats.CompressedSize), with(typ)...)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestNodesDiscovery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{
+			"cluster_name": "elasticsearch",
+			"nodes": {
+				"n1": {
+					"name": "es-1",
+					"host": "10.0.0.1",
+					"roles": ["master"],
+					"discovery": {
+						"cluster_state_queue": {"total": 3, "pending": 1, "committed": 2},
+						"published_cluster_states": {"full_states": 2, "incompatible_diffs": 1, "compatible_diffs": 40},
+						"cluster_state_update": {
+							"unchanged": {"count": 10, "computation_time_millis": 50, "notification_time_millis": 5},
+							"success": {"count": 40, "computation_time_millis": 200, "publication_time_millis": 4000, "commit_time_millis": 1500}
+						},
+						"serialized_cluster_states": {
+							"full_states": {"count": 2, "uncompressed_size_in_bytes": 20000, "compressed_size_in_bytes": 4000},
+							"diffs": {"count": 40, "uncompressed_size_in_bytes": 8000, "compressed_size_in_bytes": 2000}
+						}
+					}
+				}
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP elasticsearch_discovery_cluster_state_queue Number of cluster states in the queue of the node by state
+# TYPE elasticsearch_discovery_cluster_state_queue gauge
+elasticsearch_discovery_cluster_state_queue{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",state="committed"} 2
+elasticsearch_discovery_cluster_state_queue{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",state="pending"} 1
+# HELP elasticsearch_discovery_published_cluster_states_total Number of cluster states published by the node by type
+# TYPE elasticsearch_discovery_published_cluster_states_total counter
+elasticsearch_discovery_published_cluster_states_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",type="compatible_diff"} 40
+elasticsearch_discovery_published_cluster_states_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",type="full"} 2
+elasticsearch_discovery_published_cluster_states_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",type="incompatible_diff"} 1
+# HELP elasticsearch_discovery_cluster_state_updates_total Number of cluster state updates computed by the elected master by outcome
+# TYPE elasticsearch_discovery_cluster_state_updates_total counter
+elasticsearch_discovery_cluster_state_updates_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",outcome="success"} 40
+elasticsearch_discovery_cluster_state_updates_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",outcome="unchanged"} 10
+# HELP elasticsearch_discovery_cluster_state_update_seconds_total Time spent by the elected master in the phases of cluster state updates by outcome
+# TYPE elasticsearch_discovery_cluster_state_update_seconds_total counter
+elasticsearch_discovery_cluster_state_update_seconds_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",outcome="success",phase="commit"} 1.5
+elasticsearch_discovery_cluster_state_update_seconds_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",outcome="success",phase="computation"} 0.2
+elasticsearch_discovery_cluster_state_update_seconds_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",outcome="success",phase="publication"} 4
+elasticsearch_discovery_cluster_state_update_seconds_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",outcome="unchanged",phase="computation"} 0.05
+elasticsearch_discovery_cluster_state_update_seconds_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",outcome="unchanged",phase="notification"} 0.005
+# HELP elasticsearch_discovery_serialized_cluster_states_compressed_bytes_total Size of the cluster states serialized by the node after compression by type
+# TYPE elasticsearch_discovery_serialized_cluster_states_compressed_bytes_total counter
+elasticsearch_discovery_serialized_cluster_states_compressed_bytes_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",type="diff"} 2000
+elasticsearch_discovery_serialized_cluster_states_compressed_bytes_total{cluster="elasticsearch",es_client_node="false",es_data_node="false",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",type="full"} 4000
+`
+	if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(want),
+		"elasticsearch_discovery_cluster_state_queue", "elasticsearch_discovery_published_cluster_states_total",
+		"elasticsearch_discovery_cluster_state_updates_total", "elasticsearch_discovery_cluster_state_update_seconds_total",
+		"elasticsearch_discovery_serialized_cluster_states_compressed_bytes_total"); err != nil {
+		t.Fatal(err)
+	}
+}
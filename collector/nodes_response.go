@@ -32,15 +32,89 @@ type NodeStatsNodeResponse struct {
 	Attributes       map[string]string                            `json:"attributes"`
 	Indices          NodeStatsIndicesResponse                     `json:"indices"`
 	OS               NodeStatsOSResponse                          `json:"os"`
-	Network          NodeStatsNetworkResponse                     `json:"network"`
+	Network          *NodeStatsNetworkResponse                    `json:"network"`
 	FS               NodeStatsFSResponse                          `json:"fs"`
 	ThreadPool       map[string]NodeStatsThreadPoolPoolResponse   `json:"thread_pool"`
 	JVM              NodeStatsJVMResponse                         `json:"jvm"`
 	Breakers         map[string]NodeStatsBreakersResponse         `json:"breakers"`
-	HTTP             map[string]interface{}                       `json:"http"`
+	HTTP             *NodeStatsHTTPResponse                       `json:"http"`
 	Transport        NodeStatsTransportResponse                   `json:"transport"`
 	Process          NodeStatsProcessResponse                     `json:"process"`
 	IndexingPressure map[string]NodeStatsIndexingPressureResponse `json:"indexing_pressure"`
+	Script           NodeStatsScriptResponse                      `json:"script"`
+	Discovery        NodeStatsDiscoveryResponse                   `json:"discovery"`
+	// AdaptiveSelection is keyed by the ID of the node searches are sent to.
+	AdaptiveSelection map[string]NodeStatsAdaptiveSelectionResponse `json:"adaptive_selection"`
+	ScriptCache       *NodeStatsScriptCacheResponse                 `json:"script_cache"`
+}
+
+// NodeStatsAdaptiveSelectionResponse is a representation of the adaptive replica selection statistics a node keeps about another node
+type NodeStatsAdaptiveSelectionResponse struct {
+	OutgoingSearches  int64  `json:"outgoing_searches"`
+	AvgQueueSize      int64  `json:"avg_queue_size"`
+	AvgServiceTimeNs  int64  `json:"avg_service_time_ns"`
+	AvgResponseTimeNs int64  `json:"avg_response_time_ns"`
+	Rank              string `json:"rank"`
+}
+
+// NodeStatsDiscoveryResponse is a representation of the discovery and cluster state publication statistics
+type NodeStatsDiscoveryResponse struct {
+	ClusterStateQueue      *NodeStatsDiscoveryQueueResponse     `json:"cluster_state_queue"`
+	PublishedClusterStates *NodeStatsDiscoveryPublishedResponse `json:"published_cluster_states"`
+	// ClusterStateUpdate holds the count and the time spent in each phase,
+	// as <phase>_time_millis, of the cluster state updates by outcome.
+	ClusterStateUpdate      map[string]map[string]int64                 `json:"cluster_state_update"`
+	SerializedClusterStates *NodeStatsDiscoverySerializedStatesResponse `json:"serialized_cluster_states"`
+}
+
+// NodeStatsDiscoveryQueueResponse defines the cluster states queued on a node
+type NodeStatsDiscoveryQueueResponse struct {
+	Total     int64 `json:"total"`
+	Pending   int64 `json:"pending"`
+	Committed int64 `json:"committed"`
+}
+
+// NodeStatsDiscoveryPublishedResponse defines the cluster states published by a node
+type NodeStatsDiscoveryPublishedResponse struct {
+	FullStates        int64 `json:"full_states"`
+	IncompatibleDiffs int64 `json:"incompatible_diffs"`
+	CompatibleDiffs   int64 `json:"compatible_diffs"`
+}
+
+// NodeStatsDiscoverySerializedStatesResponse defines the cluster states serialized by a node
+type NodeStatsDiscoverySerializedStatesResponse struct {
+	FullStates NodeStatsDiscoverySerializedResponse `json:"full_states"`
+	Diffs      NodeStatsDiscoverySerializedResponse `json:"diffs"`
+}
+
+// NodeStatsDiscoverySerializedResponse defines the number and size of serialized cluster states or diffs
+type NodeStatsDiscoverySerializedResponse struct {
+	Count            int64 `json:"count"`
+	UncompressedSize int64 `json:"uncompressed_size_in_bytes"`
+	CompressedSize   int64 `json:"compressed_size_in_bytes"`
+}
+
+// NodeStatsScriptResponse is a representation of the script compilation statistics
+type NodeStatsScriptResponse struct {
+	Compilations              int64 `json:"compilations"`
+	CacheEvictions            int64 `json:"cache_evictions"`
+	CompilationLimitTriggered int64 `json:"compilation_limit_triggered"`
+	// Contexts is reported from Elasticsearch 8.x on, before that the per
+	// context stats are part of ScriptCache.
+	Contexts []NodeStatsScriptContextResponse `json:"contexts"`
+}
+
+// NodeStatsScriptCacheResponse is a representation of the script cache statistics of Elasticsearch 7.x
+type NodeStatsScriptCacheResponse struct {
+	Contexts []NodeStatsScriptContextResponse `json:"contexts"`
+}
+
+// NodeStatsScriptContextResponse is a representation of the script compilation statistics of a script context
+type NodeStatsScriptContextResponse struct {
+	Context                   string `json:"context"`
+	Compilations              int64  `json:"compilations"`
+	CacheEvictions            int64  `json:"cache_evictions"`
+	CompilationLimitTriggered int64  `json:"compilation_limit_triggered"`
 }
 
 // NodeStatsBreakersResponse is a representation of a statistics about the field data circuit breaker
@@ -65,11 +139,26 @@ type NodeStatsIndexingPressureCurrentResponse struct {
 // NodeStatsJVMResponse is a representation of a JVM stats, memory pool information, garbage collection, buffer pools, number of loaded/unloaded classes
 type NodeStatsJVMResponse struct {
 	BufferPools map[string]NodeStatsJVMBufferPoolResponse `json:"buffer_pools"`
+	Classes     NodeStatsJVMClassesResponse               `json:"classes"`
 	GC          NodeStatsJVMGCResponse                    `json:"gc"`
 	Mem         NodeStatsJVMMemResponse                   `json:"mem"`
+	Threads     NodeStatsJVMThreadsResponse               `json:"threads"`
 	Uptime      int64                                     `json:"uptime_in_millis"`
 }
 
+// NodeStatsJVMClassesResponse defines node stats JVM class loading information structure
+type NodeStatsJVMClassesResponse struct {
+	CurrentLoaded int64 `json:"current_loaded_count"`
+	TotalLoaded   int64 `json:"total_loaded_count"`
+	TotalUnloaded int64 `json:"total_unloaded_count"`
+}
+
+// NodeStatsJVMThreadsResponse defines node stats JVM threads information structure
+type NodeStatsJVMThreadsResponse struct {
+	Count     int64 `json:"count"`
+	PeakCount int64 `json:"peak_count"`
+}
+
 // NodeStatsJVMGCResponse defines node stats JVM garbage collector information structure
 type NodeStatsJVMGCResponse struct {
 	Collectors map[string]NodeStatsJVMGCCollectorResponse `json:"collectors"`
@@ -93,6 +182,7 @@ type NodeStatsJVMMemResponse struct {
 	HeapCommitted    int64                                  `json:"heap_committed_in_bytes"`
 	HeapUsed         int64                                  `json:"heap_used_in_bytes"`
 	HeapMax          int64                                  `json:"heap_max_in_bytes"`
+	HeapUsedPercent  int64                                  `json:"heap_used_percent"`
 	NonHeapCommitted int64                                  `json:"non_heap_committed_in_bytes"`
 	NonHeapUsed      int64                                  `json:"non_heap_used_in_bytes"`
 	Pools            map[string]NodeStatsJVMMemPoolResponse `json:"pools"`
@@ -106,7 +196,8 @@ type NodeStatsJVMMemPoolResponse struct {
 	PeakMax  int64 `json:"peak_max_in_bytes"`
 }
 
-// NodeStatsNetworkResponse defines node stats network information structure
+// NodeStatsNetworkResponse defines node stats network information structure,
+// which is only reported by Elasticsearch 1.x
 type NodeStatsNetworkResponse struct {
 	TCP NodeStatsTCPResponse `json:"tcp"`
 }
@@ -118,6 +209,32 @@ type NodeStatsTransportResponse struct {
 	RxSize     int64 `json:"rx_size_in_bytes"`
 	TxCount    int64 `json:"tx_count"`
 	TxSize     int64 `json:"tx_size_in_bytes"`
+	// The following fields are only reported from Elasticsearch 8.x on.
+	InboundHandlingTimeHistogram  []NodeStatsTimeHistogramBucketResponse      `json:"inbound_handling_time_histogram"`
+	OutboundHandlingTimeHistogram []NodeStatsTimeHistogramBucketResponse      `json:"outbound_handling_time_histogram"`
+	Actions                       map[string]NodeStatsTransportActionResponse `json:"actions"`
+}
+
+// NodeStatsTransportActionResponse defines the transport stats of an action
+type NodeStatsTransportActionResponse struct {
+	Requests  NodeStatsTransportActionSizeResponse `json:"requests"`
+	Responses NodeStatsTransportActionSizeResponse `json:"responses"`
+}
+
+// NodeStatsTransportActionSizeResponse defines the number and size of the requests or responses of a transport action
+type NodeStatsTransportActionSizeResponse struct {
+	Count         int64                                  `json:"count"`
+	TotalSize     int64                                  `json:"total_size_in_bytes"`
+	SizeHistogram []NodeStatsSizeHistogramBucketResponse `json:"histogram"`
+}
+
+// NodeStatsTimeHistogramBucketResponse is a bucket of an Elasticsearch time
+// histogram, counting the values in [ge_millis, lt_millis). The last bucket
+// has no upper bound.
+type NodeStatsTimeHistogramBucketResponse struct {
+	GeMillis int64 `json:"ge_millis"`
+	LtMillis int64 `json:"lt_millis"`
+	Count    int64 `json:"count"`
 }
 
 // NodeStatsThreadPoolPoolResponse is a representation of a statistics about each thread pool, including current size, queue and rejected tasks
@@ -294,6 +411,31 @@ type NodeStatsOSResponse struct {
 	CPU     NodeStatsOSCPUResponse  `json:"cpu"`
 	Mem     NodeStatsOSMemResponse  `json:"mem"`
 	Swap    NodeStatsOSSwapResponse `json:"swap"`
+	// Cgroup is only reported on Linux when Elasticsearch runs in a control
+	// group, e.g. in a container.
+	Cgroup *NodeStatsOSCgroupResponse `json:"cgroup"`
+}
+
+// NodeStatsOSCgroupResponse defines node stats operating system control group structure
+type NodeStatsOSCgroupResponse struct {
+	CPUAcct struct {
+		UsageNanos int64 `json:"usage_nanos"`
+	} `json:"cpuacct"`
+	CPU struct {
+		CFSPeriodMicros int64 `json:"cfs_period_micros"`
+		CFSQuotaMicros  int64 `json:"cfs_quota_micros"`
+		Stat            struct {
+			ElapsedPeriods     int64 `json:"number_of_elapsed_periods"`
+			TimesThrottled     int64 `json:"number_of_times_throttled"`
+			TimeThrottledNanos int64 `json:"time_throttled_nanos"`
+		} `json:"stat"`
+	} `json:"cpu"`
+	// Memory values are reported as strings as they may be "max" with
+	// cgroups v2 when no limit is set.
+	Memory struct {
+		LimitInBytes string `json:"limit_in_bytes"`
+		UsageInBytes string `json:"usage_in_bytes"`
+	} `json:"memory"`
 }
 
 // NodeStatsOSMemResponse defines node stats operating system memory usage structure
@@ -347,8 +489,49 @@ type NodeStatsProcessCPUResponse struct {
 
 // NodeStatsHTTPResponse defines node stats HTTP connections structure
 type NodeStatsHTTPResponse struct {
-	CurrentOpen int64 `json:"current_open"`
-	TotalOpen   int64 `json:"total_open"`
+	CurrentOpen int64                                 `json:"current_open"`
+	TotalOpened int64                                 `json:"total_opened"`
+	Routes      map[string]NodeStatsHTTPRouteResponse `json:"routes"`
+	Clients     []NodeStatsHTTPClientResponse         `json:"clients"`
+}
+
+// NodeStatsHTTPClientResponse is a representation of an HTTP client of a node.
+// Elasticsearch keeps closed clients in the list for a while, they have the
+// closed time set.
+type NodeStatsHTTPClientResponse struct {
+	ID               int64  `json:"id"`
+	Agent            string `json:"agent"`
+	LocalAddress     string `json:"local_address"`
+	RemoteAddress    string `json:"remote_address"`
+	LastURI          string `json:"last_uri"`
+	OpenedTime       int64  `json:"opened_time_millis"`
+	ClosedTime       int64  `json:"closed_time_millis"`
+	LastRequestTime  int64  `json:"last_request_time_millis"`
+	RequestCount     int64  `json:"request_count"`
+	RequestSizeBytes int64  `json:"request_size_bytes"`
+}
+
+// NodeStatsHTTPRouteResponse is a representation of the requests to and
+// responses of a single REST route, reported since Elasticsearch 8.9
+type NodeStatsHTTPRouteResponse struct {
+	Requests  NodeStatsHTTPRouteSizeResponse `json:"requests"`
+	Responses NodeStatsHTTPRouteSizeResponse `json:"responses"`
+}
+
+// NodeStatsHTTPRouteSizeResponse defines the number and size of the requests or responses of a route
+type NodeStatsHTTPRouteSizeResponse struct {
+	Count         int64                                  `json:"count"`
+	TotalSize     int64                                  `json:"total_size_in_bytes"`
+	SizeHistogram []NodeStatsSizeHistogramBucketResponse `json:"size_histogram"`
+}
+
+// NodeStatsSizeHistogramBucketResponse is a bucket of an Elasticsearch size
+// histogram, counting the values in [ge_bytes, lt_bytes). The last bucket has
+// no upper bound.
+type NodeStatsSizeHistogramBucketResponse struct {
+	GeBytes int64 `json:"ge_bytes"`
+	LtBytes int64 `json:"lt_bytes"`
+	Count   int64 `json:"count"`
 }
 
 // NodeStatsFSResponse is a representation of a file system information, data path, free disk space, read/write stats
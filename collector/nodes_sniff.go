@@ -0,0 +1,179 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/prometheus-community/elasticsearch_exporter/pkg/roundtripper"
+)
+
+var nodeScrapeSuccessDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "node", "scrape_success"),
+	"elasticsearch_exporter: Whether the stats of a sniffed node were fetched successfully.",
+	defaultRoleLabels, nil,
+)
+
+// nodesHTTPResponse is a representation of the Elasticsearch _nodes/http API.
+type nodesHTTPResponse struct {
+	ClusterName string                           `json:"cluster_name"`
+	Nodes       map[string]nodesHTTPNodeResponse `json:"nodes"`
+}
+
+type nodesHTTPNodeResponse struct {
+	Name string `json:"name"`
+	Host string `json:"host"`
+	HTTP struct {
+		PublishAddress string `json:"publish_address"`
+	} `json:"http"`
+}
+
+// nodeHTTPAddress returns the host:port to reach a node on from its HTTP
+// publish address. Nodes that publish a hostname report it as
+// "hostname/ip:port", in which case the hostname is used so that TLS
+// certificates issued for it remain valid.
+func nodeHTTPAddress(publishAddress string) string {
+	host, addr, ok := strings.Cut(publishAddress, "/")
+	if !ok {
+		return publishAddress
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return addr
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// sniffNodes returns the nodes of the cluster that have HTTP enabled.
+func (c *Nodes) sniffNodes(ctx context.Context) (nodesHTTPResponse, error) {
+	var nhr nodesHTTPResponse
+
+	u := *c.url
+	u.Path = path.Join(u.Path, "_nodes/http")
+	q := u.Query()
+	q.Set("filter_path", "cluster_name,nodes.*.name,nodes.*.host,nodes.*.http.publish_address")
+	u.RawQuery = q.Encode()
+
+	if err := getAndDecodeURL(ctx, c.client, c.logger, u.String(), &nhr); err != nil {
+		return nhr, err
+	}
+	return nhr, nil
+}
+
+// fetchLocalNodeStats queries the stats of the node with the given ID from the
+// node itself.
+func (c *Nodes) fetchLocalNodeStats(ctx context.Context, nodeID string, node nodesHTTPNodeResponse, version semver.Version) (nodeStatsResponse, error) {
+	var nsr nodeStatsResponse
+
+	if c.sniffTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.sniffTimeout)
+		defer cancel()
+	}
+
+	// The node is queried directly, so neither the path of es.uri, which
+	// may be the prefix of a proxy, nor failover between the endpoints of
+	// es.uri applies.
+	ctx = roundtripper.WithoutFailover(ctx)
+	u := url.URL{
+		Scheme: c.url.Scheme,
+		User:   c.url.User,
+		Host:   nodeHTTPAddress(node.HTTP.PublishAddress),
+	}
+
+	if err := getAndDecodeURL(ctx, c.client, c.logger, c.nodeStatsURL(u, "_local", version), &nsr); err != nil {
+		return nsr, err
+	}
+	// The publish address may lead to another node, e.g. through a proxy.
+	// Only keep the stats of the expected node so that no node is reported
+	// twice.
+	stats, ok := nsr.Nodes[nodeID]
+	if !ok {
+		return nsr, fmt.Errorf("%s answered for another node", u.Host)
+	}
+	nsr.Nodes = map[string]NodeStatsNodeResponse{nodeID: stats}
+	return nsr, nil
+}
+
+// updateSniffed discovers the nodes of the cluster and queries each of them
+// concurrently, reporting per node whether its stats could be fetched. Only
+// if no node could be queried is the update considered failed.
+func (c *Nodes) updateSniffed(ctx context.Context, ch chan<- prometheus.Metric, version semver.Version) error {
+	nhr, err := c.sniffNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sniff nodes: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		nodesResolvedMetric,
+		prometheus.GaugeValue,
+		float64(len(nhr.Nodes)),
+		nhr.ClusterName,
+	)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		queried int
+		errs    []error
+		sem     = make(chan struct{}, max(c.sniffParallel, 1))
+	)
+	for nodeID, node := range nhr.Nodes {
+		if node.HTTP.PublishAddress == "" {
+			c.logger.Debug("skipping node without HTTP publish address", "node", node.Name)
+			continue
+		}
+
+		queried++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			success := 1.0
+			nsr, err := c.fetchLocalNodeStats(ctx, nodeID, node, version)
+			if err != nil {
+				c.logger.Warn("failed to fetch node stats", "node", node.Name, "err", err)
+				success = 0
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("node %s: %w", node.Name, err))
+				mu.Unlock()
+			} else {
+				c.emitNodeMetrics(ch, nsr.ClusterName, nodeID, nsr.Nodes[nodeID])
+			}
+			ch <- prometheus.MustNewConstMetric(
+				nodeScrapeSuccessDesc,
+				prometheus.GaugeValue,
+				success,
+				nhr.ClusterName, node.Host, node.Name, nodeID,
+			)
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 && len(errs) == queried {
+		return fmt.Errorf("failed to fetch node stats from all nodes: %w", errors.Join(errs...))
+	}
+	return nil
+}
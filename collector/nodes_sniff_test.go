@@ -0,0 +1,103 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestNodeHTTPAddress(t *testing.T) {
+	tests := []struct {
+		publish string
+		want    string
+	}{
+		{"10.0.0.1:9200", "10.0.0.1:9200"},
+		{"es-1.example.com/10.0.0.1:9200", "es-1.example.com:9200"},
+		{"/10.0.0.1:9200", "10.0.0.1:9200"},
+		{"[::1]:9200", "[::1]:9200"},
+	}
+	for _, tt := range tests {
+		if got := nodeHTTPAddress(tt.publish); got != tt.want {
+			t.Errorf("nodeHTTPAddress(%q) = %q, want %q", tt.publish, got, tt.want)
+		}
+	}
+}
+
+func TestNodesSniff(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_nodes/_local/stats" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "../fixtures/nodestats/7.13.1.json")
+	}))
+	defer node.Close()
+	nodeURL, _ := url.Parse(node.URL)
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL, _ := url.Parse(down.URL)
+	down.Close()
+
+	seed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/proxy/_nodes/http" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{
+			"cluster_name": "elasticsearch",
+			"nodes": {
+				"byoDEtBRSRGZyMKaIpmhCQ": {"name": "aaf5a8a0bceb", "host": "172.17.0.2", "http": {"publish_address": "localhost/%s"}},
+				"down-node-id": {"name": "es-2", "host": "172.17.0.3", "http": {"publish_address": "%s"}},
+				"no-http-id": {"name": "es-3", "host": "172.17.0.4"}
+			}
+		}`, nodeURL.Host, downURL.Host)
+	}))
+	defer seed.Close()
+	// The path prefix of es.uri, e.g. of a proxy, does not apply to the
+	// nodes, which are queried directly.
+	u, _ := url.Parse(seed.URL + "/proxy")
+
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := c.(*Nodes)
+	n.sniff = true
+	n.sniffParallel = 1
+	n.sniffTimeout = 5 * time.Second
+
+	want := `# HELP elasticsearch_node_scrape_success elasticsearch_exporter: Whether the stats of a sniffed node were fetched successfully.
+# TYPE elasticsearch_node_scrape_success gauge
+elasticsearch_node_scrape_success{cluster="elasticsearch",host="172.17.0.2",name="aaf5a8a0bceb",node="byoDEtBRSRGZyMKaIpmhCQ"} 1
+elasticsearch_node_scrape_success{cluster="elasticsearch",host="172.17.0.3",name="es-2",node="down-node-id"} 0
+# HELP elasticsearch_nodes_resolved Number of nodes matched by the node selector
+# TYPE elasticsearch_nodes_resolved gauge
+elasticsearch_nodes_resolved{cluster="elasticsearch"} 3
+# HELP elasticsearch_os_load1 Shortterm load average
+# TYPE elasticsearch_os_load1 gauge
+elasticsearch_os_load1{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 2.74
+`
+	if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(want), "elasticsearch_node_scrape_success", "elasticsearch_nodes_resolved", "elasticsearch_os_load1"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func nodeStatsFixtures(tb testing.TB) []string {
+	tb.Helper()
+	files, err := filepath.Glob("../fixtures/nodestats/*.json")
+	if err != nil || len(files) == 0 {
+		tb.Fatalf("no nodestats fixtures found: %v", err)
+	}
+	return files
+}
+
+// TestStreamNodeStatsEquivalence verifies that decoding the nodes stats one
+// node at a time (streamNodeStats) yields exactly the same per-node data as a
+// whole-response json.Unmarshal into nodeStatsResponse.
+func TestStreamNodeStatsEquivalence(t *testing.T) {
+	for _, file := range nodeStatsFixtures(t) {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want nodeStatsResponse
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]NodeStatsNodeResponse{}
+			clusterName, err := streamNodeStats(bytes.NewReader(data), func(clusterName, nodeID string, node NodeStatsNodeResponse) {
+				if clusterName != want.ClusterName {
+					t.Errorf("expected cluster name %q for node %s, got %q", want.ClusterName, nodeID, clusterName)
+				}
+				got[nodeID] = node
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if clusterName != want.ClusterName {
+				t.Errorf("expected cluster name %q, got %q", want.ClusterName, clusterName)
+			}
+			if !reflect.DeepEqual(got, want.Nodes) {
+				t.Fatalf("streamed node stats differ from json.Unmarshal result")
+			}
+		})
+	}
+}
+
+// buildNodesPayload returns a nodes stats response with n copies of the node
+// of the given fixture, to measure how the decoding scales with cluster size.
+func buildNodesPayload(tb testing.TB, file string, n int) []byte {
+	tb.Helper()
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		tb.Fatalf("read fixture: %v", err)
+	}
+	var doc struct {
+		ClusterName string                     `json:"cluster_name"`
+		Nodes       map[string]json.RawMessage `json:"nodes"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		tb.Fatalf("unmarshal fixture: %v", err)
+	}
+	var node json.RawMessage
+	for _, node = range doc.Nodes {
+		break
+	}
+
+	var sb []byte
+	sb = fmt.Appendf(sb, `{"_nodes":{"total":%d,"successful":%d,"failed":0},"cluster_name":%q,"nodes":{`, n, n, doc.ClusterName)
+	for i := range n {
+		if i > 0 {
+			sb = append(sb, ',')
+		}
+		sb = fmt.Appendf(sb, "%q:", fmt.Sprintf("node-%06d", i))
+		sb = append(sb, node...)
+	}
+	sb = append(sb, []byte(`}}`)...)
+	return sb
+}
+
+// BenchmarkNodeStatsDecode contrasts reading the whole nodes stats response
+// and unmarshalling it into the map of all nodes with the streaming decode
+// used by the collector, for every fixture in fixtures/nodestats scaled to
+// clusters of different sizes. With streaming only one node is held at a
+// time, which shows in bytes/op as the number of nodes grows.
+func BenchmarkNodeStatsDecode(b *testing.B) {
+	for _, file := range nodeStatsFixtures(b) {
+		for _, n := range []int{1, 10, 200} {
+			data := buildNodesPayload(b, file, n)
+			label := fmt.Sprintf("%s/nodes_%d_(%dKB)", filepath.Base(file), n, len(data)/1024)
+
+			b.Run(label+"/readall_unmarshal_map", func(b *testing.B) {
+				b.SetBytes(int64(len(data)))
+				b.ReportAllocs()
+				var sink float64
+				for i := 0; i < b.N; i++ {
+					buf, err := io.ReadAll(bytes.NewReader(data))
+					if err != nil {
+						b.Fatal(err)
+					}
+					var nsr nodeStatsResponse
+					if err := json.Unmarshal(buf, &nsr); err != nil {
+						b.Fatal(err)
+					}
+					for _, node := range nsr.Nodes {
+						sink += float64(node.JVM.Mem.HeapUsed)
+					}
+				}
+				_ = sink
+			})
+
+			b.Run(label+"/streaming", func(b *testing.B) {
+				b.SetBytes(int64(len(data)))
+				b.ReportAllocs()
+				var sink float64
+				for i := 0; i < b.N; i++ {
+					if _, err := streamNodeStats(bytes.NewReader(data), func(_, _ string, node NodeStatsNodeResponse) {
+						sink += float64(node.JVM.Mem.HeapUsed)
+					}); err != nil {
+						b.Fatal(err)
+					}
+				}
+				_ = sink
+			})
+		}
+	}
+}
@@ -14,16 +14,24 @@
 package collector
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/promslog"
+
+	"github.com/prometheus-community/elasticsearch_exporter/cluster"
 )
 
 func TestIsDataNode(t *testing.T) {
@@ -51,6 +59,58 @@ func TestIsDataNode(t *testing.T) {
 	}
 }
 
+func TestParseCgroupBytes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"1110376448", 1110376448, true},
+		{"9223372036854771712", 9223372036854771712, true},
+		{"max", math.Inf(1), true},
+		{"", 0, false},
+		{"invalid", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseCgroupBytes(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("parseCgroupBytes(%q) = %v, %t, want %v, %t", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNodesCgroupMemoryMissing(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{
+			"cluster_name": "elasticsearch",
+			"nodes": {
+				"n1": {
+					"name": "es-1",
+					"host": "10.0.0.1",
+					"roles": ["data"],
+					"os": {"cgroup": {"cpuacct": {"usage_nanos": 1000000000}, "memory": {"control_group": "/"}}}
+				}
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := testutil.CollectAndCount(wrapCollector{c}, "elasticsearch_os_cgroup_memory_limit_bytes", "elasticsearch_os_cgroup_memory_usage_bytes"); n != 0 {
+		t.Errorf("expected no memory metrics without memory values, got %d", n)
+	}
+	if n := testutil.CollectAndCount(wrapCollector{c}, "elasticsearch_os_cgroup_cpuacct_usage_seconds_total"); n != 1 {
+		t.Errorf("expected the CPU usage to be exported, got %d", n)
+	}
+}
+
 func TestNodesStats(t *testing.T) {
 	tests := []struct {
 		name string
@@ -89,6 +149,10 @@ func TestNodesStats(t *testing.T) {
             elasticsearch_breakers_tripped{breaker="in_flight_requests",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 0
             elasticsearch_breakers_tripped{breaker="parent",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 0
             elasticsearch_breakers_tripped{breaker="request",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 0
+            # HELP elasticsearch_discovery_cluster_state_queue Number of cluster states in the queue of the node by state
+            # TYPE elasticsearch_discovery_cluster_state_queue gauge
+            elasticsearch_discovery_cluster_state_queue{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx",state="committed"} 0
+            elasticsearch_discovery_cluster_state_queue{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx",state="pending"} 0
             # HELP elasticsearch_filesystem_data_available_bytes Available space on block device in bytes
             # TYPE elasticsearch_filesystem_data_available_bytes gauge
             elasticsearch_filesystem_data_available_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",mount="/usr/share/elasticsearch/data (/dev/mapper/vg0-root)",name="bVrN1Hx",path="/usr/share/elasticsearch/data/nodes/0"} 7.7533405184e+10
@@ -113,6 +177,12 @@ func TestNodesStats(t *testing.T) {
             # HELP elasticsearch_filesystem_io_stats_device_write_size_kilobytes_sum Total kilobytes written to disk
             # TYPE elasticsearch_filesystem_io_stats_device_write_size_kilobytes_sum counter
             elasticsearch_filesystem_io_stats_device_write_size_kilobytes_sum{cluster="elasticsearch",device="dm-2",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 17760
+            # HELP elasticsearch_http_current_open Current number of open HTTP connections
+            # TYPE elasticsearch_http_current_open gauge
+            elasticsearch_http_current_open{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 1
+            # HELP elasticsearch_http_opened_total Total number of opened HTTP connections
+            # TYPE elasticsearch_http_opened_total counter
+            elasticsearch_http_opened_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 16
             # HELP elasticsearch_indices_completion_size_in_bytes Completion in bytes
             # TYPE elasticsearch_indices_completion_size_in_bytes counter
             elasticsearch_indices_completion_size_in_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 0
@@ -324,6 +394,15 @@ func TestNodesStats(t *testing.T) {
             # TYPE elasticsearch_jvm_buffer_pool_used_bytes gauge
             elasticsearch_jvm_buffer_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx",type="direct"} 2.52727869e+08
             elasticsearch_jvm_buffer_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx",type="mapped"} 15007
+            # HELP elasticsearch_jvm_classes_loaded Number of classes currently loaded by the JVM
+            # TYPE elasticsearch_jvm_classes_loaded gauge
+            elasticsearch_jvm_classes_loaded{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 10502
+            # HELP elasticsearch_jvm_classes_loaded_total Total number of classes loaded since the JVM started
+            # TYPE elasticsearch_jvm_classes_loaded_total counter
+            elasticsearch_jvm_classes_loaded_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 10502
+            # HELP elasticsearch_jvm_classes_unloaded_total Total number of classes unloaded since the JVM started
+            # TYPE elasticsearch_jvm_classes_unloaded_total counter
+            elasticsearch_jvm_classes_unloaded_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 0
             # HELP elasticsearch_jvm_gc_collection_seconds_count Count of JVM GC runs
             # TYPE elasticsearch_jvm_gc_collection_seconds_count counter
             elasticsearch_jvm_gc_collection_seconds_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",gc="old",host="127.0.0.1",name="bVrN1Hx"} 1
@@ -336,6 +415,9 @@ func TestNodesStats(t *testing.T) {
             # TYPE elasticsearch_jvm_memory_committed_bytes gauge
             elasticsearch_jvm_memory_committed_bytes{area="heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 2.077753344e+09
             elasticsearch_jvm_memory_committed_bytes{area="non-heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 7.5362304e+07
+            # HELP elasticsearch_jvm_memory_heap_used_percent Percentage of the maximum heap currently in use
+            # TYPE elasticsearch_jvm_memory_heap_used_percent gauge
+            elasticsearch_jvm_memory_heap_used_percent{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 16
             # HELP elasticsearch_jvm_memory_max_bytes JVM memory max
             # TYPE elasticsearch_jvm_memory_max_bytes gauge
             elasticsearch_jvm_memory_max_bytes{area="heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 2.077753344e+09
@@ -359,13 +441,25 @@ func TestNodesStats(t *testing.T) {
             elasticsearch_jvm_memory_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx",pool="old"} 2.10051288e+08
             elasticsearch_jvm_memory_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx",pool="survivor"} 6.9730304e+07
             elasticsearch_jvm_memory_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx",pool="young"} 5.3925336e+07
+            # HELP elasticsearch_jvm_memory_pressure Percentage of the old generation pool in use, as used by Elastic to alert on heap pressure
+            # TYPE elasticsearch_jvm_memory_pressure gauge
+            elasticsearch_jvm_memory_pressure{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 14.490385170660687
             # HELP elasticsearch_jvm_memory_used_bytes JVM memory currently used by area
             # TYPE elasticsearch_jvm_memory_used_bytes gauge
             elasticsearch_jvm_memory_used_bytes{area="heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 3.33706928e+08
             elasticsearch_jvm_memory_used_bytes{area="non-heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 7.0212664e+07
+            # HELP elasticsearch_jvm_threads Current number of live JVM threads
+            # TYPE elasticsearch_jvm_threads gauge
+            elasticsearch_jvm_threads{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 60
+            # HELP elasticsearch_jvm_threads_peak Peak number of live JVM threads
+            # TYPE elasticsearch_jvm_threads_peak gauge
+            elasticsearch_jvm_threads_peak{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 60
             # HELP elasticsearch_jvm_uptime_seconds JVM process uptime in seconds
             # TYPE elasticsearch_jvm_uptime_seconds gauge
             elasticsearch_jvm_uptime_seconds{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx",type="mapped"} 14.845
+            # HELP elasticsearch_nodes_resolved Number of nodes matched by the node selector
+            # TYPE elasticsearch_nodes_resolved gauge
+            elasticsearch_nodes_resolved{cluster="elasticsearch"} 1
             # HELP elasticsearch_nodes_roles Node roles
             # TYPE elasticsearch_nodes_roles gauge
             elasticsearch_nodes_roles{cluster="elasticsearch",host="127.0.0.1",name="bVrN1Hx",node="bVrN1HxvQLy795ZLNg2XNw",role="client"} 1
@@ -425,6 +519,15 @@ func TestNodesStats(t *testing.T) {
             # HELP elasticsearch_process_open_files_count Open file descriptors
             # TYPE elasticsearch_process_open_files_count gauge
             elasticsearch_process_open_files_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 308
+            # HELP elasticsearch_script_cache_evictions_total Number of scripts evicted from the script cache
+            # TYPE elasticsearch_script_cache_evictions_total counter
+            elasticsearch_script_cache_evictions_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 0
+            # HELP elasticsearch_script_compilation_limit_triggered_total Number of times script compilation was rejected by the compilation rate limit
+            # TYPE elasticsearch_script_compilation_limit_triggered_total counter
+            elasticsearch_script_compilation_limit_triggered_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 0
+            # HELP elasticsearch_script_compilations_total Number of scripts compiled
+            # TYPE elasticsearch_script_compilations_total counter
+            elasticsearch_script_compilations_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx"} 0
             # HELP elasticsearch_thread_pool_active_count Thread Pool threads active
             # TYPE elasticsearch_thread_pool_active_count gauge
             elasticsearch_thread_pool_active_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="127.0.0.1",name="bVrN1Hx",type="bulk"} 0
@@ -575,6 +678,12 @@ func TestNodesStats(t *testing.T) {
              # HELP elasticsearch_filesystem_data_size_bytes Size of block device in bytes
              # TYPE elasticsearch_filesystem_data_size_bytes gauge
              elasticsearch_filesystem_data_size_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",mount="/ (overlay)",name="9_P7yui",path="/usr/share/elasticsearch/data/nodes/0"} 4.76630163456e+11
+             # HELP elasticsearch_http_current_open Current number of open HTTP connections
+             # TYPE elasticsearch_http_current_open gauge
+             elasticsearch_http_current_open{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 1
+             # HELP elasticsearch_http_opened_total Total number of opened HTTP connections
+             # TYPE elasticsearch_http_opened_total counter
+             elasticsearch_http_opened_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 16
              # HELP elasticsearch_indices_completion_size_in_bytes Completion in bytes
              # TYPE elasticsearch_indices_completion_size_in_bytes counter
              elasticsearch_indices_completion_size_in_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 0
@@ -786,6 +895,15 @@ func TestNodesStats(t *testing.T) {
              # TYPE elasticsearch_jvm_buffer_pool_used_bytes gauge
              elasticsearch_jvm_buffer_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui",type="direct"} 1.68849056e+08
              elasticsearch_jvm_buffer_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui",type="mapped"} 15179
+             # HELP elasticsearch_jvm_classes_loaded Number of classes currently loaded by the JVM
+             # TYPE elasticsearch_jvm_classes_loaded gauge
+             elasticsearch_jvm_classes_loaded{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 16278
+             # HELP elasticsearch_jvm_classes_loaded_total Total number of classes loaded since the JVM started
+             # TYPE elasticsearch_jvm_classes_loaded_total counter
+             elasticsearch_jvm_classes_loaded_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 16278
+             # HELP elasticsearch_jvm_classes_unloaded_total Total number of classes unloaded since the JVM started
+             # TYPE elasticsearch_jvm_classes_unloaded_total counter
+             elasticsearch_jvm_classes_unloaded_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 0
              # HELP elasticsearch_jvm_gc_collection_seconds_count Count of JVM GC runs
              # TYPE elasticsearch_jvm_gc_collection_seconds_count counter
              elasticsearch_jvm_gc_collection_seconds_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",gc="old",host="172.17.0.2",name="9_P7yui"} 0
@@ -798,6 +916,9 @@ func TestNodesStats(t *testing.T) {
              # TYPE elasticsearch_jvm_memory_committed_bytes gauge
              elasticsearch_jvm_memory_committed_bytes{area="heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 1.073741824e+09
              elasticsearch_jvm_memory_committed_bytes{area="non-heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 1.179648e+08
+             # HELP elasticsearch_jvm_memory_heap_used_percent Percentage of the maximum heap currently in use
+             # TYPE elasticsearch_jvm_memory_heap_used_percent gauge
+             elasticsearch_jvm_memory_heap_used_percent{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 61
              # HELP elasticsearch_jvm_memory_max_bytes JVM memory max
              # TYPE elasticsearch_jvm_memory_max_bytes gauge
              elasticsearch_jvm_memory_max_bytes{area="heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 1.073741824e+09
@@ -821,13 +942,25 @@ func TestNodesStats(t *testing.T) {
              elasticsearch_jvm_memory_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui",pool="old"} 2.55827968e+08
              elasticsearch_jvm_memory_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui",pool="survivor"} 1.1010048e+07
              elasticsearch_jvm_memory_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui",pool="young"} 3.93216e+08
+             # HELP elasticsearch_jvm_memory_pressure Percentage of the old generation pool in use, as used by Elastic to alert on heap pressure
+             # TYPE elasticsearch_jvm_memory_pressure gauge
+             elasticsearch_jvm_memory_pressure{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 23.825836181640625
              # HELP elasticsearch_jvm_memory_used_bytes JVM memory currently used by area
              # TYPE elasticsearch_jvm_memory_used_bytes gauge
              elasticsearch_jvm_memory_used_bytes{area="heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 6.60054016e+08
              elasticsearch_jvm_memory_used_bytes{area="non-heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 1.08594112e+08
+             # HELP elasticsearch_jvm_threads Current number of live JVM threads
+             # TYPE elasticsearch_jvm_threads gauge
+             elasticsearch_jvm_threads{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 54
+             # HELP elasticsearch_jvm_threads_peak Peak number of live JVM threads
+             # TYPE elasticsearch_jvm_threads_peak gauge
+             elasticsearch_jvm_threads_peak{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 54
              # HELP elasticsearch_jvm_uptime_seconds JVM process uptime in seconds
              # TYPE elasticsearch_jvm_uptime_seconds gauge
              elasticsearch_jvm_uptime_seconds{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui",type="mapped"} 16.456
+             # HELP elasticsearch_nodes_resolved Number of nodes matched by the node selector
+             # TYPE elasticsearch_nodes_resolved gauge
+             elasticsearch_nodes_resolved{cluster="elasticsearch"} 1
              # HELP elasticsearch_nodes_roles Node roles
              # TYPE elasticsearch_nodes_roles gauge
              elasticsearch_nodes_roles{cluster="elasticsearch",host="172.17.0.2",name="9_P7yui",node="9_P7yuiySjG7OAN6NRbBRA",role="client"} 1
@@ -842,6 +975,30 @@ func TestNodesStats(t *testing.T) {
              elasticsearch_nodes_roles{cluster="elasticsearch",host="172.17.0.2",name="9_P7yui",node="9_P7yuiySjG7OAN6NRbBRA",role="ml"} 0
              elasticsearch_nodes_roles{cluster="elasticsearch",host="172.17.0.2",name="9_P7yui",node="9_P7yuiySjG7OAN6NRbBRA",role="remote_cluster_client"} 0
              elasticsearch_nodes_roles{cluster="elasticsearch",host="172.17.0.2",name="9_P7yui",node="9_P7yuiySjG7OAN6NRbBRA",role="transform"} 0
+             # HELP elasticsearch_os_cgroup_cpu_cfs_period_seconds Period of the CFS bandwidth control of the control group of the node
+             # TYPE elasticsearch_os_cgroup_cpu_cfs_period_seconds gauge
+             elasticsearch_os_cgroup_cpu_cfs_period_seconds{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 0.1
+             # HELP elasticsearch_os_cgroup_cpu_cfs_quota_seconds CPU time the control group of the node may use per CFS period, -1 if unlimited
+             # TYPE elasticsearch_os_cgroup_cpu_cfs_quota_seconds gauge
+             elasticsearch_os_cgroup_cpu_cfs_quota_seconds{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} -1
+             # HELP elasticsearch_os_cgroup_cpu_elapsed_periods_total Number of CFS periods elapsed for the control group of the node
+             # TYPE elasticsearch_os_cgroup_cpu_elapsed_periods_total counter
+             elasticsearch_os_cgroup_cpu_elapsed_periods_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 0
+             # HELP elasticsearch_os_cgroup_cpu_throttled_periods_total Number of CFS periods in which the control group of the node was throttled
+             # TYPE elasticsearch_os_cgroup_cpu_throttled_periods_total counter
+             elasticsearch_os_cgroup_cpu_throttled_periods_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 0
+             # HELP elasticsearch_os_cgroup_cpu_throttled_seconds_total Time the control group of the node was throttled for
+             # TYPE elasticsearch_os_cgroup_cpu_throttled_seconds_total counter
+             elasticsearch_os_cgroup_cpu_throttled_seconds_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 0
+             # HELP elasticsearch_os_cgroup_cpuacct_usage_seconds_total CPU time consumed by all tasks in the control group of the node
+             # TYPE elasticsearch_os_cgroup_cpuacct_usage_seconds_total counter
+             elasticsearch_os_cgroup_cpuacct_usage_seconds_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 33.206615382
+             # HELP elasticsearch_os_cgroup_memory_limit_bytes Memory limit of the control group of the node, +Inf if unlimited
+             # TYPE elasticsearch_os_cgroup_memory_limit_bytes gauge
+             elasticsearch_os_cgroup_memory_limit_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 9.223372036854772e+18
+             # HELP elasticsearch_os_cgroup_memory_usage_bytes Memory used by the control group of the node
+             # TYPE elasticsearch_os_cgroup_memory_usage_bytes gauge
+             elasticsearch_os_cgroup_memory_usage_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 1.63405824e+09
              # HELP elasticsearch_os_cpu_percent Percent CPU used by OS
              # TYPE elasticsearch_os_cpu_percent gauge
              elasticsearch_os_cpu_percent{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 30
@@ -887,6 +1044,15 @@ func TestNodesStats(t *testing.T) {
              # HELP elasticsearch_process_open_files_count Open file descriptors
              # TYPE elasticsearch_process_open_files_count gauge
              elasticsearch_process_open_files_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 355
+             # HELP elasticsearch_script_cache_evictions_total Number of scripts evicted from the script cache
+             # TYPE elasticsearch_script_cache_evictions_total counter
+             elasticsearch_script_cache_evictions_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 0
+             # HELP elasticsearch_script_compilation_limit_triggered_total Number of times script compilation was rejected by the compilation rate limit
+             # TYPE elasticsearch_script_compilation_limit_triggered_total counter
+             elasticsearch_script_compilation_limit_triggered_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 0
+             # HELP elasticsearch_script_compilations_total Number of scripts compiled
+             # TYPE elasticsearch_script_compilations_total counter
+             elasticsearch_script_compilations_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui"} 1
              # HELP elasticsearch_thread_pool_active_count Thread Pool threads active
              # TYPE elasticsearch_thread_pool_active_count gauge
              elasticsearch_thread_pool_active_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="9_P7yui",type="analyze"} 0
@@ -1086,6 +1252,15 @@ func TestNodesStats(t *testing.T) {
              elasticsearch_breakers_tripped{breaker="model_inference",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
              elasticsearch_breakers_tripped{breaker="parent",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
              elasticsearch_breakers_tripped{breaker="request",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             # HELP elasticsearch_discovery_cluster_state_queue Number of cluster states in the queue of the node by state
+             # TYPE elasticsearch_discovery_cluster_state_queue gauge
+             elasticsearch_discovery_cluster_state_queue{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",state="committed"} 0
+             elasticsearch_discovery_cluster_state_queue{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",state="pending"} 0
+             # HELP elasticsearch_discovery_published_cluster_states_total Number of cluster states published by the node by type
+             # TYPE elasticsearch_discovery_published_cluster_states_total counter
+             elasticsearch_discovery_published_cluster_states_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",type="compatible_diff"} 49
+             elasticsearch_discovery_published_cluster_states_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",type="full"} 2
+             elasticsearch_discovery_published_cluster_states_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",type="incompatible_diff"} 0
              # HELP elasticsearch_filesystem_data_available_bytes Available space on block device in bytes
              # TYPE elasticsearch_filesystem_data_available_bytes gauge
              elasticsearch_filesystem_data_available_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",mount="/ (overlay)",name="aaf5a8a0bceb",path="/usr/share/elasticsearch/data/nodes/0"} 6.3425642496e+10
@@ -1101,6 +1276,20 @@ func TestNodesStats(t *testing.T) {
              # HELP elasticsearch_indexing_pressure_limit_in_bytes Configured memory limit, in bytes, for the indexing requests
              # TYPE elasticsearch_indexing_pressure_limit_in_bytes gauge
              elasticsearch_indexing_pressure_limit_in_bytes{cluster="elasticsearch",host="172.17.0.2",indexing_pressure="memory",name="aaf5a8a0bceb"} 7.8852915e+07
+             # HELP elasticsearch_http_client_requests Number of requests sent by the HTTP clients tracked by the node by state
+             # TYPE elasticsearch_http_client_requests gauge
+             elasticsearch_http_client_requests{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",state="closed"} 15
+             elasticsearch_http_client_requests{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",state="open"} 1
+             # HELP elasticsearch_http_clients Number of HTTP clients tracked by the node by state, closed clients are kept by Elasticsearch for a while
+             # TYPE elasticsearch_http_clients gauge
+             elasticsearch_http_clients{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",state="closed"} 15
+             elasticsearch_http_clients{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",state="open"} 1
+             # HELP elasticsearch_http_current_open Current number of open HTTP connections
+             # TYPE elasticsearch_http_current_open gauge
+             elasticsearch_http_current_open{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 1
+             # HELP elasticsearch_http_opened_total Total number of opened HTTP connections
+             # TYPE elasticsearch_http_opened_total counter
+             elasticsearch_http_opened_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 16
              # HELP elasticsearch_indices_completion_size_in_bytes Completion in bytes
              # TYPE elasticsearch_indices_completion_size_in_bytes counter
              elasticsearch_indices_completion_size_in_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
@@ -1312,6 +1501,15 @@ func TestNodesStats(t *testing.T) {
              # TYPE elasticsearch_jvm_buffer_pool_used_bytes gauge
              elasticsearch_jvm_buffer_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",type="direct"} 8.811046e+06
              elasticsearch_jvm_buffer_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",type="mapped"} 16868
+             # HELP elasticsearch_jvm_classes_loaded Number of classes currently loaded by the JVM
+             # TYPE elasticsearch_jvm_classes_loaded gauge
+             elasticsearch_jvm_classes_loaded{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 21909
+             # HELP elasticsearch_jvm_classes_loaded_total Total number of classes loaded since the JVM started
+             # TYPE elasticsearch_jvm_classes_loaded_total counter
+             elasticsearch_jvm_classes_loaded_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 21909
+             # HELP elasticsearch_jvm_classes_unloaded_total Total number of classes unloaded since the JVM started
+             # TYPE elasticsearch_jvm_classes_unloaded_total counter
+             elasticsearch_jvm_classes_unloaded_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
              # HELP elasticsearch_jvm_gc_collection_seconds_count Count of JVM GC runs
              # TYPE elasticsearch_jvm_gc_collection_seconds_count counter
              elasticsearch_jvm_gc_collection_seconds_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",gc="old",host="172.17.0.2",name="aaf5a8a0bceb"} 0
@@ -1324,6 +1522,9 @@ func TestNodesStats(t *testing.T) {
              # TYPE elasticsearch_jvm_memory_committed_bytes gauge
              elasticsearch_jvm_memory_committed_bytes{area="heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 7.88529152e+08
              elasticsearch_jvm_memory_committed_bytes{area="non-heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 1.42606336e+08
+             # HELP elasticsearch_jvm_memory_heap_used_percent Percentage of the maximum heap currently in use
+             # TYPE elasticsearch_jvm_memory_heap_used_percent gauge
+             elasticsearch_jvm_memory_heap_used_percent{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 19
              # HELP elasticsearch_jvm_memory_max_bytes JVM memory max
              # TYPE elasticsearch_jvm_memory_max_bytes gauge
              elasticsearch_jvm_memory_max_bytes{area="heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 7.88529152e+08
@@ -1347,13 +1548,25 @@ func TestNodesStats(t *testing.T) {
              elasticsearch_jvm_memory_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",pool="old"} 7.1059968e+07
              elasticsearch_jvm_memory_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",pool="survivor"} 3.0608512e+07
              elasticsearch_jvm_memory_pool_used_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",pool="young"} 5.4525952e+07
+             # HELP elasticsearch_jvm_memory_pressure Percentage of the old generation pool in use, as used by Elastic to alert on heap pressure
+             # TYPE elasticsearch_jvm_memory_pressure gauge
+             elasticsearch_jvm_memory_pressure{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 9.011710958277925
              # HELP elasticsearch_jvm_memory_used_bytes JVM memory currently used by area
              # TYPE elasticsearch_jvm_memory_used_bytes gauge
              elasticsearch_jvm_memory_used_bytes{area="heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 1.56194432e+08
              elasticsearch_jvm_memory_used_bytes{area="non-heap",cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 1.39526472e+08
+             # HELP elasticsearch_jvm_threads Current number of live JVM threads
+             # TYPE elasticsearch_jvm_threads gauge
+             elasticsearch_jvm_threads{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 49
+             # HELP elasticsearch_jvm_threads_peak Peak number of live JVM threads
+             # TYPE elasticsearch_jvm_threads_peak gauge
+             elasticsearch_jvm_threads_peak{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 49
              # HELP elasticsearch_jvm_uptime_seconds JVM process uptime in seconds
              # TYPE elasticsearch_jvm_uptime_seconds gauge
              elasticsearch_jvm_uptime_seconds{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",type="mapped"} 21.844
+             # HELP elasticsearch_nodes_resolved Number of nodes matched by the node selector
+             # TYPE elasticsearch_nodes_resolved gauge
+             elasticsearch_nodes_resolved{cluster="elasticsearch"} 1
              # HELP elasticsearch_nodes_roles Node roles
              # TYPE elasticsearch_nodes_roles gauge
              elasticsearch_nodes_roles{cluster="elasticsearch",host="172.17.0.2",name="aaf5a8a0bceb",node="byoDEtBRSRGZyMKaIpmhCQ",role="client"} 1
@@ -1368,6 +1581,30 @@ func TestNodesStats(t *testing.T) {
              elasticsearch_nodes_roles{cluster="elasticsearch",host="172.17.0.2",name="aaf5a8a0bceb",node="byoDEtBRSRGZyMKaIpmhCQ",role="ml"} 1
              elasticsearch_nodes_roles{cluster="elasticsearch",host="172.17.0.2",name="aaf5a8a0bceb",node="byoDEtBRSRGZyMKaIpmhCQ",role="remote_cluster_client"} 1
              elasticsearch_nodes_roles{cluster="elasticsearch",host="172.17.0.2",name="aaf5a8a0bceb",node="byoDEtBRSRGZyMKaIpmhCQ",role="transform"} 1
+             # HELP elasticsearch_os_cgroup_cpu_cfs_period_seconds Period of the CFS bandwidth control of the control group of the node
+             # TYPE elasticsearch_os_cgroup_cpu_cfs_period_seconds gauge
+             elasticsearch_os_cgroup_cpu_cfs_period_seconds{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0.1
+             # HELP elasticsearch_os_cgroup_cpu_cfs_quota_seconds CPU time the control group of the node may use per CFS period, -1 if unlimited
+             # TYPE elasticsearch_os_cgroup_cpu_cfs_quota_seconds gauge
+             elasticsearch_os_cgroup_cpu_cfs_quota_seconds{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} -1
+             # HELP elasticsearch_os_cgroup_cpu_elapsed_periods_total Number of CFS periods elapsed for the control group of the node
+             # TYPE elasticsearch_os_cgroup_cpu_elapsed_periods_total counter
+             elasticsearch_os_cgroup_cpu_elapsed_periods_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             # HELP elasticsearch_os_cgroup_cpu_throttled_periods_total Number of CFS periods in which the control group of the node was throttled
+             # TYPE elasticsearch_os_cgroup_cpu_throttled_periods_total counter
+             elasticsearch_os_cgroup_cpu_throttled_periods_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             # HELP elasticsearch_os_cgroup_cpu_throttled_seconds_total Time the control group of the node was throttled for
+             # TYPE elasticsearch_os_cgroup_cpu_throttled_seconds_total counter
+             elasticsearch_os_cgroup_cpu_throttled_seconds_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             # HELP elasticsearch_os_cgroup_cpuacct_usage_seconds_total CPU time consumed by all tasks in the control group of the node
+             # TYPE elasticsearch_os_cgroup_cpuacct_usage_seconds_total counter
+             elasticsearch_os_cgroup_cpuacct_usage_seconds_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 52.445263941
+             # HELP elasticsearch_os_cgroup_memory_limit_bytes Memory limit of the control group of the node, +Inf if unlimited
+             # TYPE elasticsearch_os_cgroup_memory_limit_bytes gauge
+             elasticsearch_os_cgroup_memory_limit_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 9.223372036854772e+18
+             # HELP elasticsearch_os_cgroup_memory_usage_bytes Memory used by the control group of the node
+             # TYPE elasticsearch_os_cgroup_memory_usage_bytes gauge
+             elasticsearch_os_cgroup_memory_usage_bytes{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 1.110376448e+09
              # HELP elasticsearch_os_cpu_percent Percent CPU used by OS
              # TYPE elasticsearch_os_cpu_percent gauge
              elasticsearch_os_cpu_percent{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 37
@@ -1413,6 +1650,126 @@ func TestNodesStats(t *testing.T) {
              # HELP elasticsearch_process_open_files_count Open file descriptors
              # TYPE elasticsearch_process_open_files_count gauge
              elasticsearch_process_open_files_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 314
+             # HELP elasticsearch_script_cache_evictions_total Number of scripts evicted from the script cache
+             # TYPE elasticsearch_script_cache_evictions_total counter
+             elasticsearch_script_cache_evictions_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             # HELP elasticsearch_script_compilation_limit_triggered_total Number of times script compilation was rejected by the compilation rate limit
+             # TYPE elasticsearch_script_compilation_limit_triggered_total counter
+             elasticsearch_script_compilation_limit_triggered_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             # HELP elasticsearch_script_compilations_total Number of scripts compiled
+             # TYPE elasticsearch_script_compilations_total counter
+             elasticsearch_script_compilations_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 1
+             # HELP elasticsearch_script_context_cache_evictions_total Number of scripts of the script context evicted from the script cache
+             # TYPE elasticsearch_script_context_cache_evictions_total counter
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="aggregation_selector",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="aggs",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="aggs_combine",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="aggs_init",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="aggs_map",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="aggs_reduce",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="analysis",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="boolean_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="bucket_aggregation",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="date_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="double_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="filter",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="geo_point_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="ingest",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="ingest_template",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="interval",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="ip_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="keyword_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="long_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="moving-function",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="number_sort",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="painless_test",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="processor_conditional",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="score",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="script_heuristic",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="similarity",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="similarity_weight",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="string_sort",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="template",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="terms_set",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="update",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="watcher_condition",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="watcher_transform",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_cache_evictions_total{cluster="elasticsearch",context="xpack_template",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             # HELP elasticsearch_script_context_compilation_limit_triggered_total Number of times script compilation in the script context was rejected by the compilation rate limit
+             # TYPE elasticsearch_script_context_compilation_limit_triggered_total counter
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="aggregation_selector",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="aggs",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="aggs_combine",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="aggs_init",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="aggs_map",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="aggs_reduce",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="analysis",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="boolean_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="bucket_aggregation",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="date_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="double_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="filter",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="geo_point_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="ingest",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="ingest_template",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="interval",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="ip_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="keyword_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="long_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="moving-function",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="number_sort",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="painless_test",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="processor_conditional",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="score",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="script_heuristic",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="similarity",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="similarity_weight",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="string_sort",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="template",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="terms_set",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="update",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="watcher_condition",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="watcher_transform",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="xpack_template",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             # HELP elasticsearch_script_context_compilations_total Number of scripts compiled in the script context
+             # TYPE elasticsearch_script_context_compilations_total counter
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="aggregation_selector",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="aggs",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="aggs_combine",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="aggs_init",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="aggs_map",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="aggs_reduce",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="analysis",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="boolean_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="bucket_aggregation",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="date_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="double_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="filter",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="geo_point_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="ingest",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 1
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="ingest_template",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="interval",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="ip_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="keyword_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="long_field",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="moving-function",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="number_sort",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="painless_test",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="processor_conditional",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="score",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="script_heuristic",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="similarity",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="similarity_weight",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="string_sort",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="template",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="terms_set",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="update",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="watcher_condition",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="watcher_transform",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
+             elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="xpack_template",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb"} 0
              # HELP elasticsearch_thread_pool_active_count Thread Pool threads active
              # TYPE elasticsearch_thread_pool_active_count gauge
              elasticsearch_thread_pool_active_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="true",es_master_node="true",host="172.17.0.2",name="aaf5a8a0bceb",type="analyze"} 0
@@ -1627,14 +1984,443 @@ func TestNodesStats(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			c := NewNodes(promslog.NewNopLogger(), http.DefaultClient, u, true, "_local")
+			c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			if err := testutil.CollectAndCompare(c, strings.NewReader(tt.want)); err != nil {
+			if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(tt.want)); err != nil {
 				t.Fatal(err)
 			}
 		})
 	}
 }
+
+func TestNodesSelector(t *testing.T) {
+	var requestURI string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		io.WriteString(w, `{"cluster_name": "elasticsearch", "nodes": {}}`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.(*Nodes).configure(targetSettings{nodes: []string{"data_hot:true", "attr.rack:rack 1"}})
+
+	want := `# HELP elasticsearch_nodes_resolved Number of nodes matched by the node selector
+# TYPE elasticsearch_nodes_resolved gauge
+elasticsearch_nodes_resolved{cluster="elasticsearch"} 0
+`
+	if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "/_nodes/data_hot:true,attr.rack:rack%201/stats"; requestURI != want {
+		t.Errorf("expected request to %s, got %s", want, requestURI)
+	}
+}
+
+func TestNodesMetricGroups(t *testing.T) {
+	var requestURI string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		http.ServeFile(w, r, "../fixtures/nodestats/7.13.1.json")
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(metrics []string) { esNodesMetrics = metrics }(esNodesMetrics)
+	esNodesMetrics = []string{"jvm"}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(wrapCollector{c})
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		name := mf.GetName()
+		if !strings.HasPrefix(name, "elasticsearch_jvm") && !strings.HasPrefix(name, "elasticsearch_nodes_") {
+			t.Errorf("unexpected metric %s outside of the jvm group", name)
+		}
+	}
+
+	want := "/_nodes/stats/jvm,http?filter_path=" + url.QueryEscape("cluster_name,nodes.*.name,nodes.*.host,nodes.*.timestamp,nodes.*.transport_address,nodes.*.roles,nodes.*.attributes,nodes.*.jvm,nodes.*.http.current_open")
+	if requestURI != want {
+		t.Errorf("expected request to %s, got %s", want, requestURI)
+	}
+
+	esNodesMetrics = []string{"jvm,unknown"}
+	if _, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient); err == nil {
+		t.Error("expected error for unknown metric group")
+	}
+}
+
+func TestNodesMetricGroupScript(t *testing.T) {
+	fixture, err := os.ReadFile("../fixtures/nodestats/7.13.1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats struct {
+		ClusterName string                                `json:"cluster_name"`
+		Nodes       map[string]map[string]json.RawMessage `json:"nodes"`
+	}
+	if err := json.Unmarshal(fixture, &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server only returns the node sections named in filter_path, like
+	// Elasticsearch does.
+	var requestPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		filterPath := strings.Split(r.URL.Query().Get("filter_path"), ",")
+		nodes := map[string]map[string]json.RawMessage{}
+		for id, node := range stats.Nodes {
+			nodes[id] = map[string]json.RawMessage{}
+			for field, value := range node {
+				if slices.ContainsFunc(filterPath, func(f string) bool {
+					return f == "nodes.*."+field || strings.HasPrefix(f, "nodes.*."+field+".")
+				}) {
+					nodes[id][field] = value
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"cluster_name": stats.ClusterName, "nodes": nodes})
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(metrics []string) { esNodesMetrics = metrics }(esNodesMetrics)
+	esNodesMetrics = []string{"script"}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Elasticsearch 7.x reports the per-context stats in script_cache.
+	uc := &mockUpdateContext{info: cluster.Info{Version: cluster.VersionInfo{Number: "7.13.1"}}}
+	if n := testutil.CollectAndCount(wrapCollectorWithContext{c, uc}, "elasticsearch_script_context_compilations_total"); n != 35 {
+		t.Errorf("expected compilations of 35 script contexts, got %d", n)
+	}
+	if want := "/_nodes/stats/script,script_cache,http"; requestPath != want {
+		t.Errorf("expected request to %s, got %s", want, requestPath)
+	}
+
+	// Elasticsearch before 7.9 rejects script_cache, as do clusters of
+	// unknown version.
+	for _, version := range []string{"7.8.0", ""} {
+		uc := &mockUpdateContext{info: cluster.Info{Version: cluster.VersionInfo{Number: version}}}
+		testutil.CollectAndCount(wrapCollectorWithContext{c, uc})
+		if want := "/_nodes/stats/script,http"; requestPath != want {
+			t.Errorf("version %q: expected request to %s, got %s", version, want, requestPath)
+		}
+	}
+}
+
+func TestNodesHTTPRoutes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{
+			"cluster_name": "elasticsearch",
+			"nodes": {
+				"n1": {
+					"name": "es-1",
+					"host": "10.0.0.1",
+					"roles": ["master", "data"],
+					"http": {
+						"current_open": 3,
+						"total_opened": 120,
+						"routes": {
+							"/_bulk": {
+								"requests": {
+									"count": 5,
+									"total_size_in_bytes": 9000,
+									"size_histogram": [
+										{"ge_bytes": 1024, "lt_bytes": 2048, "count": 3},
+										{"lt_bytes": 1024, "count": 1},
+										{"ge_bytes": 2048, "count": 1}
+									]
+								},
+								"responses": {
+									"count": 5,
+									"total_size_in_bytes": 500,
+									"size_histogram": [{"ge_bytes": 64, "lt_bytes": 128, "count": 5}]
+								}
+							}
+						}
+					}
+				}
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeLabels := `cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",route="/_bulk",le="%s"`
+	want := `# HELP elasticsearch_http_route_request_size_bytes Size of the HTTP requests to a REST route
+# TYPE elasticsearch_http_route_request_size_bytes histogram
+` + bucketLines("elasticsearch_http_route_request_size_bytes", routeLabels, sizeHistogramBucketCount, 1, map[int64]uint64{1024: 1, 2048: 4}) + `elasticsearch_http_route_request_size_bytes_bucket{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",route="/_bulk",le="+Inf"} 5
+elasticsearch_http_route_request_size_bytes_sum{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",route="/_bulk"} 9000
+elasticsearch_http_route_request_size_bytes_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",route="/_bulk"} 5
+# HELP elasticsearch_http_route_response_size_bytes Size of the HTTP responses of a REST route
+# TYPE elasticsearch_http_route_response_size_bytes histogram
+` + bucketLines("elasticsearch_http_route_response_size_bytes", routeLabels, sizeHistogramBucketCount, 1, map[int64]uint64{128: 5}) + `elasticsearch_http_route_response_size_bytes_bucket{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",route="/_bulk",le="+Inf"} 5
+elasticsearch_http_route_response_size_bytes_sum{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",route="/_bulk"} 500
+elasticsearch_http_route_response_size_bytes_count{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",route="/_bulk"} 5
+# HELP elasticsearch_http_current_open Current number of open HTTP connections
+# TYPE elasticsearch_http_current_open gauge
+elasticsearch_http_current_open{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1"} 3
+# HELP elasticsearch_http_opened_total Total number of opened HTTP connections
+# TYPE elasticsearch_http_opened_total counter
+elasticsearch_http_opened_total{cluster="elasticsearch",es_client_node="true",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1"} 120
+`
+	if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(want),
+		"elasticsearch_http_route_request_size_bytes", "elasticsearch_http_route_response_size_bytes",
+		"elasticsearch_http_current_open", "elasticsearch_http_opened_total"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNodesJVMMemoryPressure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{
+			"cluster_name": "elasticsearch",
+			"nodes": {
+				"n1": {
+					"name": "es-1",
+					"host": "10.0.0.1",
+					"roles": ["master", "data"],
+					"jvm": {"mem": {"pools": {"old": {"used_in_bytes": 256, "max_in_bytes": 1024}}}}
+				},
+				"n2": {
+					"name": "es-2",
+					"host": "10.0.0.2",
+					"roles": ["master", "data"],
+					"jvm": {"mem": {"pools": {"young": {"used_in_bytes": 256, "max_in_bytes": 0}}}}
+				},
+				"n3": {
+					"name": "es-3",
+					"host": "10.0.0.3",
+					"roles": ["master", "data"],
+					"jvm": {"mem": {"pools": {"old": {"used_in_bytes": 256, "max_in_bytes": -1}}}}
+				}
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nodes without a bounded old generation pool have no memory pressure.
+	want := `# HELP elasticsearch_jvm_memory_pressure Percentage of the old generation pool in use, as used by Elastic to alert on heap pressure
+# TYPE elasticsearch_jvm_memory_pressure gauge
+elasticsearch_jvm_memory_pressure{cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1"} 25
+`
+	if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(want), "elasticsearch_jvm_memory_pressure"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// bucketLines returns the buckets of a histogram with the fixed layout of n
+// power-of-two bounds used by Elasticsearch, divided by scale. labels is a
+// format with a %s verb for the le label. counts holds the cumulative count
+// starting at a bound, the buckets below the first one are 0.
+func bucketLines(metric, labels string, n int, scale float64, counts map[int64]uint64) string {
+	var b strings.Builder
+	var count uint64
+	for i := 0; i < n; i++ {
+		bound := int64(1) << i
+		if c, ok := counts[bound]; ok {
+			count = c
+		}
+		le := strconv.FormatFloat(float64(bound)/scale, 'f', -1, 64)
+		fmt.Fprintf(&b, "%s_bucket{%s} %d\n", metric, fmt.Sprintf(labels, le), count)
+	}
+	return b.String()
+}
+
+func TestSizeHistogramBuckets(t *testing.T) {
+	buckets := sizeHistogramBuckets([]NodeStatsSizeHistogramBucketResponse{
+		{GeBytes: 4, LtBytes: 8, Count: 2},
+		{LtBytes: 1, Count: 1},
+		{GeBytes: 1 << 26, Count: 5},
+	})
+	if len(buckets) != sizeHistogramBucketCount {
+		t.Fatalf("expected %d buckets, got %d", sizeHistogramBucketCount, len(buckets))
+	}
+	for bound, want := range map[float64]uint64{1: 1, 2: 1, 4: 1, 8: 3, 1 << 26: 3} {
+		if got := buckets[bound]; got != want {
+			t.Errorf("expected %d in bucket %g, got %d", want, bound, got)
+		}
+	}
+}
+
+func TestNodesTransportStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{
+			"cluster_name": "elasticsearch",
+			"nodes": {
+				"n1": {
+					"name": "es-1",
+					"host": "10.0.0.1",
+					"roles": ["master", "data"],
+					"network": {
+						"tcp": {
+							"active_opens": 10,
+							"passive_opens": 20,
+							"curr_estab": 5,
+							"in_segs": 1000,
+							"out_segs": 900,
+							"retrans_segs": 7,
+							"estab_resets": 1,
+							"attempt_fails": 2,
+							"in_errs": 3,
+							"out_rsts": 4
+						}
+					},
+					"transport": {
+						"inbound_handling_time_histogram": [
+							{"lt_millis": 1, "count": 8},
+							{"ge_millis": 1, "lt_millis": 2, "count": 1},
+							{"ge_millis": 2, "count": 1}
+						],
+						"actions": {
+							"indices:data/write/bulk[s]": {
+								"requests": {
+									"count": 2,
+									"total_size_in_bytes": 3000,
+									"histogram": [{"ge_bytes": 1024, "lt_bytes": 2048, "count": 2}]
+								},
+								"responses": {
+									"count": 2,
+									"total_size_in_bytes": 100,
+									"histogram": [{"ge_bytes": 32, "lt_bytes": 64, "count": 2}]
+								}
+							}
+						}
+					}
+				}
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.(*Nodes).transportActions = true
+
+	nodeLabels := `cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",le="%s",name="es-1"`
+	actionLabels := `action="indices:data/write/bulk[s]",cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",le="%s"`
+	want := `# HELP elasticsearch_network_tcp_retransmitted_segments_total Number of TCP segments retransmitted
+# TYPE elasticsearch_network_tcp_retransmitted_segments_total counter
+elasticsearch_network_tcp_retransmitted_segments_total{cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1"} 7
+# HELP elasticsearch_network_tcp_established Number of currently established TCP connections
+# TYPE elasticsearch_network_tcp_established gauge
+elasticsearch_network_tcp_established{cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1"} 5
+# HELP elasticsearch_transport_inbound_handling_time_seconds_bucket Cumulative number of inbound transport messages handled on the network thread within the upper bound le, Elasticsearch does not report the sum to build a histogram
+# TYPE elasticsearch_transport_inbound_handling_time_seconds_bucket counter
+` + bucketLines("elasticsearch_transport_inbound_handling_time_seconds", nodeLabels, timeHistogramBucketCount, 1000, map[int64]uint64{1: 8, 2: 9}) + `elasticsearch_transport_inbound_handling_time_seconds_bucket{cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",le="+Inf",name="es-1"} 10
+# HELP elasticsearch_transport_action_request_size_bytes Size of the transport requests of an action
+# TYPE elasticsearch_transport_action_request_size_bytes histogram
+` + bucketLines("elasticsearch_transport_action_request_size_bytes", actionLabels, sizeHistogramBucketCount, 1, map[int64]uint64{2048: 2}) + `elasticsearch_transport_action_request_size_bytes_bucket{action="indices:data/write/bulk[s]",cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1",le="+Inf"} 2
+elasticsearch_transport_action_request_size_bytes_sum{action="indices:data/write/bulk[s]",cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1"} 3000
+elasticsearch_transport_action_request_size_bytes_count{action="indices:data/write/bulk[s]",cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="true",host="10.0.0.1",name="es-1"} 2
+`
+	if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(want),
+		"elasticsearch_network_tcp_retransmitted_segments_total", "elasticsearch_network_tcp_established",
+		"elasticsearch_transport_inbound_handling_time_seconds_bucket", "elasticsearch_transport_outbound_handling_time_seconds_bucket",
+		"elasticsearch_transport_action_request_size_bytes"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNodesScriptContexts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{
+			"cluster_name": "elasticsearch",
+			"nodes": {
+				"n1": {
+					"name": "es-1",
+					"host": "10.0.0.1",
+					"roles": ["data"],
+					"script": {
+						"compilations": 12,
+						"cache_evictions": 3,
+						"compilation_limit_triggered": 2,
+						"contexts": [
+							{"context": "score", "compilations": 10, "cache_evictions": 3, "compilation_limit_triggered": 2},
+							{"context": "update", "compilations": 2, "cache_evictions": 0, "compilation_limit_triggered": 0}
+						]
+					}
+				}
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewNodes(promslog.NewNopLogger(), u, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP elasticsearch_script_compilation_limit_triggered_total Number of times script compilation was rejected by the compilation rate limit
+# TYPE elasticsearch_script_compilation_limit_triggered_total counter
+elasticsearch_script_compilation_limit_triggered_total{cluster="elasticsearch",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="false",host="10.0.0.1",name="es-1"} 2
+# HELP elasticsearch_script_context_compilation_limit_triggered_total Number of times script compilation in the script context was rejected by the compilation rate limit
+# TYPE elasticsearch_script_context_compilation_limit_triggered_total counter
+elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="score",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="false",host="10.0.0.1",name="es-1"} 2
+elasticsearch_script_context_compilation_limit_triggered_total{cluster="elasticsearch",context="update",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="false",host="10.0.0.1",name="es-1"} 0
+# HELP elasticsearch_script_context_compilations_total Number of scripts compiled in the script context
+# TYPE elasticsearch_script_context_compilations_total counter
+elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="score",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="false",host="10.0.0.1",name="es-1"} 10
+elasticsearch_script_context_compilations_total{cluster="elasticsearch",context="update",es_client_node="false",es_data_node="true",es_ingest_node="false",es_master_node="false",host="10.0.0.1",name="es-1"} 2
+`
+	if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(want),
+		"elasticsearch_script_compilation_limit_triggered_total", "elasticsearch_script_context_compilation_limit_triggered_total",
+		"elasticsearch_script_context_compilations_total"); err != nil {
+		t.Fatal(err)
+	}
+}
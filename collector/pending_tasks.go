@@ -0,0 +1,160 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	pendingTasksLabels = []string{"cluster", "priority", "source"}
+
+	pendingTasksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pending_tasks", "count"),
+		"Number of pending cluster tasks by priority and source",
+		pendingTasksLabels, nil,
+	)
+	pendingTasksMaxTimeInQueueDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pending_tasks", "max_time_in_queue_seconds"),
+		"Longest time a pending cluster task of the priority and source has been waiting in the queue",
+		pendingTasksLabels, nil,
+	)
+	pendingTasksPriorityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pending_tasks", "priority_count"),
+		"Number of pending cluster tasks by priority, including priorities without pending tasks",
+		[]string{"cluster", "priority"}, nil,
+	)
+)
+
+// pendingTaskPriorities are the priorities of cluster tasks, which are always
+// reported so that an empty queue is visible.
+var pendingTaskPriorities = []string{"immediate", "urgent", "high", "normal", "low", "languid"}
+
+func init() {
+	registerCollector("pending-tasks", defaultDisabled, NewPendingTasks)
+}
+
+// PendingTasks information struct
+type PendingTasks struct {
+	logger *slog.Logger
+	hc     *http.Client
+	u      *url.URL
+}
+
+// NewPendingTasks defines PendingTasks Prometheus metrics
+func NewPendingTasks(logger *slog.Logger, u *url.URL, hc *http.Client) (Collector, error) {
+	return &PendingTasks{
+		logger: logger,
+		hc:     hc,
+		u:      u,
+	}, nil
+}
+
+// pendingTasksResponse is a representation of the cluster pending tasks API.
+type pendingTasksResponse struct {
+	Tasks []pendingTaskResponse `json:"tasks"`
+}
+
+type pendingTaskResponse struct {
+	Priority          string `json:"priority"`
+	Source            string `json:"source"`
+	TimeInQueueMillis int64  `json:"time_in_queue_millis"`
+}
+
+// pendingTaskGroup identifies the pending tasks of a priority and source.
+type pendingTaskGroup struct {
+	priority string
+	source   string
+}
+
+type pendingTaskStats struct {
+	count            int64
+	maxTimeInQueueMS int64
+}
+
+// pendingTaskSource returns the type of a pending task from its source,
+// e.g. put-mapping for "put-mapping [index/uuid]" or cluster_reroute for
+// "cluster_reroute(reroute after starting shards)", so that tasks of the same
+// type are counted together regardless of the index or shard they concern.
+func pendingTaskSource(source string) string {
+	if i := strings.IndexAny(source, " [({"); i >= 0 {
+		source = source[:i]
+	}
+	if source == "" {
+		return "unknown"
+	}
+	return source
+}
+
+// Update counts the pending cluster tasks by priority and by priority and
+// source and sends them with the longest time in queue of each group to ch.
+func (p *PendingTasks) Update(ctx context.Context, uc UpdateContext, ch chan<- prometheus.Metric) error {
+	var ptr pendingTasksResponse
+
+	u := p.u.ResolveReference(&url.URL{Path: "/_cluster/pending_tasks"})
+	if err := getAndDecodeURL(ctx, p.hc, p.logger, u.String(), &ptr); err != nil {
+		return fmt.Errorf("failed to fetch and decode pending tasks: %w", err)
+	}
+
+	clusterName := getClusterName(ctx, uc)
+
+	priorities := map[string]int64{}
+	for _, priority := range pendingTaskPriorities {
+		priorities[priority] = 0
+	}
+	groups := map[pendingTaskGroup]pendingTaskStats{}
+	for _, task := range ptr.Tasks {
+		group := pendingTaskGroup{
+			priority: strings.ToLower(task.Priority),
+			source:   pendingTaskSource(task.Source),
+		}
+		priorities[group.priority]++
+		stats := groups[group]
+		stats.count++
+		stats.maxTimeInQueueMS = max(stats.maxTimeInQueueMS, task.TimeInQueueMillis)
+		groups[group] = stats
+	}
+
+	for priority, count := range priorities {
+		ch <- prometheus.MustNewConstMetric(
+			pendingTasksPriorityDesc,
+			prometheus.GaugeValue,
+			float64(count),
+			clusterName, priority,
+		)
+	}
+	for group, stats := range groups {
+		ch <- prometheus.MustNewConstMetric(
+			pendingTasksDesc,
+			prometheus.GaugeValue,
+			float64(stats.count),
+			clusterName, group.priority, group.source,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			pendingTasksMaxTimeInQueueDesc,
+			prometheus.GaugeValue,
+			float64(stats.maxTimeInQueueMS)/1000,
+			clusterName, group.priority, group.source,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestPendingTaskSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"put-mapping [logs-2024.01.01/AbCdEfGh]", "put-mapping"},
+		{"shard-started StartedShardEntry{shardId [[logs][0]]}", "shard-started"},
+		{"cluster_reroute(reroute after starting shards)", "cluster_reroute"},
+		{"create-index [foo_9], cause [api]", "create-index"},
+		{"", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := pendingTaskSource(tt.source); got != tt.want {
+			t.Errorf("pendingTaskSource(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestPendingTasks(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{
+			name: "8.13.0",
+			file: "8.13.0.json",
+			want: `# HELP elasticsearch_pending_tasks_count Number of pending cluster tasks by priority and source
+# TYPE elasticsearch_pending_tasks_count gauge
+elasticsearch_pending_tasks_count{cluster="unknown_cluster",priority="high",source="put-mapping"} 2
+elasticsearch_pending_tasks_count{cluster="unknown_cluster",priority="urgent",source="create-index"} 1
+elasticsearch_pending_tasks_count{cluster="unknown_cluster",priority="urgent",source="shard-started"} 1
+# HELP elasticsearch_pending_tasks_max_time_in_queue_seconds Longest time a pending cluster task of the priority and source has been waiting in the queue
+# TYPE elasticsearch_pending_tasks_max_time_in_queue_seconds gauge
+elasticsearch_pending_tasks_max_time_in_queue_seconds{cluster="unknown_cluster",priority="high",source="put-mapping"} 1.5
+elasticsearch_pending_tasks_max_time_in_queue_seconds{cluster="unknown_cluster",priority="urgent",source="create-index"} 0.086
+elasticsearch_pending_tasks_max_time_in_queue_seconds{cluster="unknown_cluster",priority="urgent",source="shard-started"} 0.02
+# HELP elasticsearch_pending_tasks_priority_count Number of pending cluster tasks by priority, including priorities without pending tasks
+# TYPE elasticsearch_pending_tasks_priority_count gauge
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="high"} 2
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="immediate"} 0
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="languid"} 0
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="low"} 0
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="normal"} 0
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="urgent"} 2
+`,
+		},
+		{
+			name: "empty",
+			file: "8.13.0-empty.json",
+			want: `# HELP elasticsearch_pending_tasks_priority_count Number of pending cluster tasks by priority, including priorities without pending tasks
+# TYPE elasticsearch_pending_tasks_priority_count gauge
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="high"} 0
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="immediate"} 0
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="languid"} 0
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="low"} 0
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="normal"} 0
+elasticsearch_pending_tasks_priority_count{cluster="unknown_cluster",priority="urgent"} 0
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(path.Join("../fixtures/pendingtasks/", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/_cluster/pending_tasks" {
+					http.NotFound(w, r)
+					return
+				}
+				io.Copy(w, f)
+			}))
+			defer ts.Close()
+
+			u, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := NewPendingTasks(promslog.NewNopLogger(), u, http.DefaultClient)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := testutil.CollectAndCompare(wrapCollector{c}, strings.NewReader(tt.want)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -14,18 +14,22 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"path"
 
 	"github.com/prometheus/client_golang/prometheus"
-
-	"github.com/prometheus-community/elasticsearch_exporter/pkg/clusterinfo"
 )
 
+func init() {
+	registerCollector("shards", defaultDisabled, NewShards)
+}
+
 // ShardResponse has shard's node and index info
 type ShardResponse struct {
 	Index string `json:"index"`
@@ -36,27 +40,14 @@ type ShardResponse struct {
 
 // Shards information struct
 type Shards struct {
-	logger          *slog.Logger
-	client          *http.Client
-	url             *url.URL
-	clusterInfoCh   chan *clusterinfo.Response
-	lastClusterInfo *clusterinfo.Response
+	logger *slog.Logger
+	client *http.Client
+	url    *url.URL
 
 	nodeShardMetrics  []*nodeShardMetric
 	jsonParseFailures prometheus.Counter
 }
 
-// ClusterLabelUpdates returns a pointer to a channel to receive cluster info updates. It implements the
-// (not exported) clusterinfo.consumer interface
-func (s *Shards) ClusterLabelUpdates() *chan *clusterinfo.Response {
-	return &s.clusterInfoCh
-}
-
-// String implements the stringer interface. It is part of the clusterinfo.consumer interface
-func (s *Shards) String() string {
-	return namespace + "shards"
-}
-
 type nodeShardMetric struct {
 	Type   prometheus.ValueType
 	Desc   *prometheus.Desc
@@ -64,59 +55,22 @@ type nodeShardMetric struct {
 	Labels labels
 }
 
-// fetchClusterNameOnce performs a single request to the root endpoint to obtain the cluster name.
-func fetchClusterNameOnce(s *Shards) string {
-	if s.lastClusterInfo != nil && s.lastClusterInfo.ClusterName != "unknown_cluster" {
-		return s.lastClusterInfo.ClusterName
-	}
-	u := *s.url
-	u.Path = path.Join(u.Path, "/")
-	resp, err := s.client.Get(u.String())
-	if err == nil {
-		defer resp.Body.Close()
-		if resp.StatusCode == http.StatusOK {
-			var root struct {
-				ClusterName string `json:"cluster_name"`
-			}
-			if err := json.NewDecoder(resp.Body).Decode(&root); err == nil && root.ClusterName != "" {
-				s.lastClusterInfo = &clusterinfo.Response{ClusterName: root.ClusterName}
-				return root.ClusterName
-			}
-		}
-	}
-	return "unknown_cluster"
-}
-
 // NewShards defines Shards Prometheus metrics
-func NewShards(logger *slog.Logger, client *http.Client, url *url.URL) *Shards {
-	var shardPtr *Shards
+func NewShards(logger *slog.Logger, url *url.URL, client *http.Client) (Collector, error) {
 	nodeLabels := labels{
 		keys: func(...string) []string {
 			return []string{"node", "cluster"}
 		},
-		values: func(lastClusterinfo *clusterinfo.Response, base ...string) []string {
-			if lastClusterinfo != nil {
-				return append(base, lastClusterinfo.ClusterName)
-			}
-			if shardPtr != nil {
-				return append(base, fetchClusterNameOnce(shardPtr))
-			}
-			return append(base, "unknown_cluster")
+		values: func(clusterName string, base ...string) []string {
+			return append(base, clusterName)
 		},
 	}
 
-	shards := &Shards{
-		// will assign later
-
+	return &Shards{
 		logger: logger,
 		client: client,
 		url:    url,
 
-		clusterInfoCh: make(chan *clusterinfo.Response),
-		lastClusterInfo: &clusterinfo.Response{
-			ClusterName: "unknown_cluster",
-		},
-
 		nodeShardMetrics: []*nodeShardMetric{
 			{
 				Type: prometheus.GaugeValue,
@@ -136,89 +90,40 @@ func NewShards(logger *slog.Logger, client *http.Client, url *url.URL) *Shards {
 			Name: prometheus.BuildFQName(namespace, "node_shards", "json_parse_failures"),
 			Help: "Number of errors while parsing JSON.",
 		}),
-	}
-
-	// start go routine to fetch clusterinfo updates and save them to lastClusterinfo
-	go func() {
-		logger.Debug("starting cluster info receive loop")
-		for ci := range shards.clusterInfoCh {
-			if ci != nil {
-				logger.Debug("received cluster info update", "cluster", ci.ClusterName)
-				shards.lastClusterInfo = ci
-			}
-		}
-		logger.Debug("exiting cluster info receive loop")
-	}()
-
-	shardPtr = shards
-	return shards
+	}, nil
 }
 
-// Describe Shards
-func (s *Shards) Describe(ch chan<- *prometheus.Desc) {
-	ch <- s.jsonParseFailures.Desc()
-
-	for _, metric := range s.nodeShardMetrics {
-		ch <- metric.Desc
-	}
-}
-
-func (s *Shards) getAndParseURL(u *url.URL) ([]ShardResponse, error) {
-	res, err := s.client.Get(u.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
-	}
-
-	defer func() {
-		err = res.Body.Close()
-		if err != nil {
-			s.logger.Warn(
-				"failed to close http.Client",
-				"err", err,
-			)
-		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP Request failed with code %d", res.StatusCode)
-	}
-	var sfr []ShardResponse
-	if err := json.NewDecoder(res.Body).Decode(&sfr); err != nil {
-		s.jsonParseFailures.Inc()
-		return nil, err
-	}
-	return sfr, nil
-}
-
-func (s *Shards) fetchAndDecodeShards() ([]ShardResponse, error) {
+func (s *Shards) fetchAndDecodeShards(ctx context.Context) ([]ShardResponse, error) {
 	u := *s.url
 	u.Path = path.Join(u.Path, "/_cat/shards")
 	q := u.Query()
 	q.Set("format", "json")
 	u.RawQuery = q.Encode()
-	sfr, err := s.getAndParseURL(&u)
-	if err != nil {
-		return sfr, err
-	}
+
+	var sfr []ShardResponse
+	err := fetchURL(ctx, s.client, s.logger, u.String(), func(r io.Reader) error {
+		if err := json.NewDecoder(r).Decode(&sfr); err != nil {
+			s.jsonParseFailures.Inc()
+			return err
+		}
+		return nil
+	})
 	return sfr, err
 }
 
-// Collect number of shards on each node
-func (s *Shards) Collect(ch chan<- prometheus.Metric) {
+// Update collects the number of shards on each node
+func (s *Shards) Update(ctx context.Context, uc UpdateContext, ch chan<- prometheus.Metric) error {
 	defer func() {
 		ch <- s.jsonParseFailures
 	}()
 
-	sr, err := s.fetchAndDecodeShards()
+	sr, err := s.fetchAndDecodeShards(ctx)
 	if err != nil {
-		s.logger.Warn(
-			"failed to fetch and decode node shards stats",
-			"err", err,
-		)
-		return
+		return fmt.Errorf("failed to fetch and decode node shards stats: %w", err)
 	}
 
+	clusterName := getClusterName(ctx, uc)
+
 	nodeShards := make(map[string]float64)
 
 	for _, shard := range sr {
@@ -233,8 +138,10 @@ func (s *Shards) Collect(ch chan<- prometheus.Metric) {
 				metric.Desc,
 				metric.Type,
 				metric.Value(shards),
-				metric.Labels.values(s.lastClusterInfo, node)...,
+				metric.Labels.values(clusterName, node)...,
 			)
 		}
 	}
+
+	return nil
 }
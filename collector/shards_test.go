@@ -70,12 +70,12 @@ func TestShards(t *testing.T) {
 				t.Fatalf("Failed to parse URL: %s", err)
 			}
 
-			s := NewShards(promslog.NewNopLogger(), http.DefaultClient, u)
+			s, err := NewShards(promslog.NewNopLogger(), u, http.DefaultClient)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			if err := testutil.CollectAndCompare(s, strings.NewReader(tt.want)); err != nil {
+			if err := testutil.CollectAndCompare(wrapCollector{s}, strings.NewReader(tt.want)); err != nil {
 				t.Fatal(err)
 			}
 		})
@@ -116,7 +116,7 @@ func (c *Snapshots) Update(ctx context.Context, uc UpdateContext, ch chan<- prom
 
 	var srr SnapshotRepositoriesResponse
 	if err := getAndDecodeURL(ctx, c.hc, c.logger, u.String(), &srr); err != nil {
-		return fmt.Errorf("failed to get snapshot repositories: %v", err)
+		return fmt.Errorf("failed to get snapshot repositories: %w", err)
 	}
 
 	for repository := range srr {
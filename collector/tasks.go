@@ -15,9 +15,7 @@ package collector
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -80,7 +78,7 @@ func (t *TaskCollector) Update(ctx context.Context, uc UpdateContext, ch chan<-
 	return nil
 }
 
-func (t *TaskCollector) fetchTasks(_ context.Context) (tasksResponse, error) {
+func (t *TaskCollector) fetchTasks(ctx context.Context) (tasksResponse, error) {
 	u := t.u.ResolveReference(&url.URL{Path: "_tasks"})
 	q := u.Query()
 	q.Set("group_by", "none")
@@ -88,28 +86,7 @@ func (t *TaskCollector) fetchTasks(_ context.Context) (tasksResponse, error) {
 	u.RawQuery = q.Encode()
 
 	var tr tasksResponse
-	res, err := t.hc.Get(u.String())
-	if err != nil {
-		return tr, fmt.Errorf("failed to get data stream stats health from %s://%s:%s%s: %s",
-			u.Scheme, u.Hostname(), u.Port(), u.Path, err)
-	}
-
-	defer func() {
-		if cerr := res.Body.Close(); cerr != nil {
-			t.logger.Warn("failed to close response body", "err", cerr)
-		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return tr, fmt.Errorf("HTTP Request to %v failed with code %d", u.String(), res.StatusCode)
-	}
-
-	bts, err := io.ReadAll(res.Body)
-	if err != nil {
-		return tr, err
-	}
-
-	if err := json.Unmarshal(bts, &tr); err != nil {
+	if err := getAndDecodeURL(ctx, t.hc, t.logger, u.String(), &tr); err != nil {
 		return tr, err
 	}
 	return tr, nil
@@ -43,3 +43,13 @@ func (c *DefaultUpdateContext) GetClusterInfo(ctx context.Context) (cluster.Info
 	}
 	return info, nil
 }
+
+// getClusterName returns the cluster name from the update context, falling
+// back to "unknown_cluster" if it cannot be determined.
+func getClusterName(ctx context.Context, uc UpdateContext) string {
+	info, err := uc.GetClusterInfo(ctx)
+	if err != nil || info.ClusterName == "" {
+		return "unknown_cluster"
+	}
+	return info.ClusterName
+}
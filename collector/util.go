@@ -43,7 +43,7 @@ func fetchURL(ctx context.Context, hc *http.Client, log *slog.Logger, u string,
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP Request failed with code %d", resp.StatusCode)
+		return &httpStatusError{code: resp.StatusCode, url: req.URL.Redacted()}
 	}
 
 	return consume(resp.Body)
@@ -58,7 +58,10 @@ func getAndDecodeURL(ctx context.Context, hc *http.Client, log *slog.Logger, u s
 		if err != nil {
 			return err
 		}
-		return json.Unmarshal(b, target)
+		if err := json.Unmarshal(b, target); err != nil {
+			return fmt.Errorf("%w: %w", errDecode, err)
+		}
+		return nil
 	})
 }
 
@@ -15,24 +15,249 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"go.yaml.in/yaml/v3"
 )
 
 // Config represents the YAML configuration file structure.
 type Config struct {
-	AuthModules map[string]AuthModule `yaml:"auth_modules"`
+	AuthModules map[string]AuthModule      `yaml:"auth_modules"`
+	Collectors  map[string]CollectorConfig `yaml:"collectors,omitempty"`
+	Targets     map[string]TargetConfig    `yaml:"targets,omitempty"`
+}
+
+// TargetConfig describes a named Elasticsearch cluster that /probe scrapes
+// when called with target_name.
+type TargetConfig struct {
+	URL        string        `yaml:"url,omitempty"`
+	URLs       []string      `yaml:"urls,omitempty"`
+	AuthModule string        `yaml:"auth_module,omitempty"`
+	TLS        *TLSConfig    `yaml:"tls,omitempty"`
+	Collectors []string      `yaml:"collectors,omitempty"`
+	ShardLevel *bool         `yaml:"shard_level,omitempty"`
+	Node       NodeSelector  `yaml:"node,omitempty"`
+	Timeout    time.Duration `yaml:"timeout,omitempty"`
+}
+
+// Endpoints returns the URLs of the target, which are either given by url or
+// by urls.
+func (t TargetConfig) Endpoints() []string {
+	if t.URL != "" {
+		return []string{t.URL}
+	}
+	return t.URLs
+}
+
+// NodeSelector is a list of Elasticsearch node filter expressions such as
+// _local, a node name, master:true or attr.zone:us-east-1a. In YAML it is
+// given either as a list or as a comma separated string.
+type NodeSelector []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (n *NodeSelector) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*n = strings.Split(value.Value, ",")
+		return nil
+	}
+	var exprs []string
+	if err := value.Decode(&exprs); err != nil {
+		return err
+	}
+	*n = exprs
+	return nil
+}
+
+// ParseNodeSelector splits comma separated node filter expressions and
+// validates them. Expressions become a path segment of the nodes APIs, so
+// those that would change the path are rejected.
+func ParseNodeSelector(exprs []string) (NodeSelector, error) {
+	var selector NodeSelector
+	for _, e := range exprs {
+		for expr := range strings.SplitSeq(e, ",") {
+			expr = strings.TrimSpace(expr)
+			switch {
+			case expr == "":
+				return nil, fmt.Errorf("empty node filter expression in %q", e)
+			case expr == "." || expr == ".." || strings.ContainsAny(expr, "/\\"):
+				return nil, fmt.Errorf("invalid node filter expression %q", expr)
+			}
+			if attr, value, ok := strings.Cut(expr, ":"); ok && (attr == "" || value == "") {
+				return nil, fmt.Errorf("node filter expression %q must have the form attribute:value", expr)
+			}
+			selector = append(selector, expr)
+		}
+	}
+	return selector, nil
+}
+
+// CollectorConfig overrides the scheduling of a single collector.
+type CollectorConfig struct {
+	Timeout     time.Duration `yaml:"timeout,omitempty"`
+	MinInterval time.Duration `yaml:"min_interval,omitempty"`
+	Mode        string        `yaml:"mode,omitempty"`
 }
 
 type AuthModule struct {
-	Type     string            `yaml:"type"`
-	UserPass *UserPassConfig   `yaml:"userpass,omitempty"`
-	APIKey   string            `yaml:"apikey,omitempty"`
-	AWS      *AWSConfig        `yaml:"aws,omitempty"`
-	TLS      *TLSConfig        `yaml:"tls,omitempty"`
-	Options  map[string]string `yaml:"options,omitempty"`
+	Type       string            `yaml:"type"`
+	UserPass   *UserPassConfig   `yaml:"userpass,omitempty"`
+	APIKey     string            `yaml:"apikey,omitempty"`
+	APIKeyFile string            `yaml:"apikey_file,omitempty"`
+	Bearer     *BearerConfig     `yaml:"bearer,omitempty"`
+	OAuth2     *OAuth2Config     `yaml:"oauth2,omitempty"`
+	AWS        *AWSConfig        `yaml:"aws,omitempty"`
+	TLS        *TLSConfig        `yaml:"tls,omitempty"`
+	Options    map[string]string `yaml:"options,omitempty"`
+}
+
+// Validate ensures the auth module has the required fields according to its
+// type. name identifies the module in errors.
+func (am *AuthModule) Validate(name string) error {
+	// Validate fields based on auth type
+	switch strings.ToLower(am.Type) {
+	case "userpass":
+		if am.UserPass == nil || am.UserPass.Username == "" || (am.UserPass.Password == "") == (am.UserPass.PasswordFile == "") {
+			return fmt.Errorf("auth_module %s type userpass requires username and either password or password_file", name)
+		}
+		if am.UserPass.PasswordFile != "" {
+			if _, err := os.Stat(am.UserPass.PasswordFile); err != nil {
+				return fmt.Errorf("auth_module %s: password_file '%s' not accessible: %w", name, am.UserPass.PasswordFile, err)
+			}
+		}
+	case "apikey":
+		if (am.APIKey == "") == (am.APIKeyFile == "") {
+			return fmt.Errorf("auth_module %s type apikey requires either apikey or apikey_file", name)
+		}
+		if am.APIKeyFile != "" {
+			if _, err := os.Stat(am.APIKeyFile); err != nil {
+				return fmt.Errorf("auth_module %s: apikey_file '%s' not accessible: %w", name, am.APIKeyFile, err)
+			}
+		}
+	case "bearer":
+		if am.Bearer == nil || (am.Bearer.Token == "") == (am.Bearer.TokenFile == "") {
+			return fmt.Errorf("auth_module %s type bearer requires either bearer.token or bearer.token_file", name)
+		}
+		if am.Bearer.TokenFile != "" {
+			if _, err := os.Stat(am.Bearer.TokenFile); err != nil {
+				return fmt.Errorf("auth_module %s: token_file '%s' not accessible: %w", name, am.Bearer.TokenFile, err)
+			}
+		}
+	case "oauth2":
+		if am.OAuth2 == nil || am.OAuth2.ClientID == "" || am.OAuth2.TokenURL == "" {
+			return fmt.Errorf("auth_module %s type oauth2 requires client_id and token_url", name)
+		}
+		if u, err := url.Parse(am.OAuth2.TokenURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("auth_module %s has invalid token_url %s", name, am.OAuth2.TokenURL)
+		}
+		if (am.OAuth2.ClientSecret == "") == (am.OAuth2.ClientSecretFile == "") {
+			return fmt.Errorf("auth_module %s type oauth2 requires either client_secret or client_secret_file", name)
+		}
+		if am.OAuth2.ClientSecretFile != "" {
+			if _, err := os.Stat(am.OAuth2.ClientSecretFile); err != nil {
+				return fmt.Errorf("auth_module %s: client_secret_file '%s' not accessible: %w", name, am.OAuth2.ClientSecretFile, err)
+			}
+		}
+	case "aws":
+		// No strict validation: region can come from environment/defaults; role_arn is optional.
+	case "tls":
+		// TLS auth type means client certificate authentication only (no other auth)
+		if am.TLS == nil {
+			return fmt.Errorf("auth_module %s type tls requires tls configuration section", name)
+		}
+		if am.TLS.CertFile == "" || am.TLS.KeyFile == "" {
+			return fmt.Errorf("auth_module %s type tls requires cert_file and key_file for client certificate authentication", name)
+		}
+		// Validate that other auth fields are not set when using TLS auth type
+		if am.UserPass != nil {
+			return fmt.Errorf("auth_module %s type tls cannot have userpass configuration", name)
+		}
+		if am.APIKey != "" || am.APIKeyFile != "" {
+			return fmt.Errorf("auth_module %s type tls cannot have apikey", name)
+		}
+		if am.AWS != nil {
+			return fmt.Errorf("auth_module %s type tls cannot have aws configuration", name)
+		}
+		if am.Bearer != nil || am.OAuth2 != nil {
+			return fmt.Errorf("auth_module %s type tls cannot have bearer or oauth2 configuration", name)
+		}
+	default:
+		return fmt.Errorf("auth_module %s has unsupported type %s", name, am.Type)
+	}
+
+	// Validate TLS configuration (optional for all auth types, provides transport security)
+	if am.TLS != nil {
+		// For cert-based auth (type: tls), cert and key are required
+		// For other auth types, TLS config is optional and used for transport security
+		if strings.ToLower(am.Type) != "tls" {
+			// For non-TLS auth types, if cert/key are provided, both must be present
+			if (am.TLS.CertFile != "") != (am.TLS.KeyFile != "") {
+				return fmt.Errorf("auth_module %s: if providing client certificate, both cert_file and key_file must be specified", name)
+			}
+		}
+
+		// Validate file accessibility
+		for fileType, path := range map[string]string{
+			"ca_file":   am.TLS.CAFile,
+			"cert_file": am.TLS.CertFile,
+			"key_file":  am.TLS.KeyFile,
+		} {
+			if path == "" {
+				continue
+			}
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("auth_module %s: %s '%s' not accessible: %w", name, fileType, path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveAPIKey returns the API key, reading it from apikey_file if set. The
+// file is read on every call so that rotated secrets are picked up.
+func (am *AuthModule) ResolveAPIKey() (string, error) {
+	if am.APIKeyFile != "" {
+		return readSecretFile(am.APIKeyFile)
+	}
+	return am.APIKey, nil
+}
+
+// BearerConfig contains a static bearer token or the file to read it from.
+type BearerConfig struct {
+	Token     string `yaml:"token,omitempty"`
+	TokenFile string `yaml:"token_file,omitempty"`
+}
+
+// ResolveToken returns the token, reading it from token_file if set. The file
+// is read on every call so that rotated tokens are picked up.
+func (b *BearerConfig) ResolveToken() (string, error) {
+	if b.TokenFile != "" {
+		return readSecretFile(b.TokenFile)
+	}
+	return b.Token, nil
+}
+
+// OAuth2Config contains settings for the OAuth2 client credentials flow.
+type OAuth2Config struct {
+	ClientID         string            `yaml:"client_id"`
+	ClientSecret     string            `yaml:"client_secret,omitempty"`
+	ClientSecretFile string            `yaml:"client_secret_file,omitempty"`
+	TokenURL         string            `yaml:"token_url"`
+	Scopes           []string          `yaml:"scopes,omitempty"`
+	EndpointParams   map[string]string `yaml:"endpoint_params,omitempty"`
+}
+
+// ResolveClientSecret returns the client secret, reading it from
+// client_secret_file if set.
+func (o *OAuth2Config) ResolveClientSecret() (string, error) {
+	if o.ClientSecretFile != "" {
+		return readSecretFile(o.ClientSecretFile)
+	}
+	return o.ClientSecret, nil
 }
 
 // AWSConfig contains settings for SigV4 authentication.
@@ -50,77 +275,169 @@ type TLSConfig struct {
 }
 
 type UserPassConfig struct {
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password,omitempty"`
+	PasswordFile string `yaml:"password_file,omitempty"`
 }
 
-// validate ensures every auth module has the required fields according to its type.
-func (c *Config) validate() error {
+// ResolvePassword returns the password, reading it from password_file if set.
+// The file is read on every call so that rotated secrets are picked up.
+func (up *UserPassConfig) ResolvePassword() (string, error) {
+	if up.PasswordFile != "" {
+		return readSecretFile(up.PasswordFile)
+	}
+	return up.Password, nil
+}
+
+// readSecretFile returns the content of a secret file without surrounding
+// whitespace such as a trailing newline.
+func readSecretFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file '%s': %w", path, err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// envPattern matches ${VAR} references and $${ escapes in config values.
+var envPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnvRefs replaces ${VAR} references in s with the value of the
+// environment variable VAR and $${ with a literal ${. Unset variables are an
+// error rather than an empty string, so that a typo does not silently result
+// in an empty secret.
+func expandEnvRefs(s string) (string, error) {
+	var err error
+	expanded := envPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		name := envPattern.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v
+	})
+	return expanded, err
+}
+
+// expandEnv expands ${VAR} references in the auth modules and targets.
+func (c *Config) expandEnv() error {
 	for name, am := range c.AuthModules {
-		// Validate fields based on auth type
-		switch strings.ToLower(am.Type) {
-		case "userpass":
-			if am.UserPass == nil || am.UserPass.Username == "" || am.UserPass.Password == "" {
-				return fmt.Errorf("auth_module %s type userpass requires username and password", name)
-			}
-		case "apikey":
-			if am.APIKey == "" {
-				return fmt.Errorf("auth_module %s type apikey requires apikey", name)
-			}
-		case "aws":
-			// No strict validation: region can come from environment/defaults; role_arn is optional.
-		case "tls":
-			// TLS auth type means client certificate authentication only (no other auth)
-			if am.TLS == nil {
-				return fmt.Errorf("auth_module %s type tls requires tls configuration section", name)
+		fields := []*string{&am.APIKey, &am.APIKeyFile}
+		if am.UserPass != nil {
+			up := *am.UserPass
+			am.UserPass = &up
+			fields = append(fields, &up.Username, &up.Password, &up.PasswordFile)
+		}
+		if am.Bearer != nil {
+			b := *am.Bearer
+			am.Bearer = &b
+			fields = append(fields, &b.Token, &b.TokenFile)
+		}
+		if am.OAuth2 != nil {
+			o := *am.OAuth2
+			am.OAuth2 = &o
+			fields = append(fields, &o.ClientID, &o.ClientSecret, &o.ClientSecretFile, &o.TokenURL)
+		}
+		for _, f := range fields {
+			v, err := expandEnvRefs(*f)
+			if err != nil {
+				return fmt.Errorf("auth_module %s: %w", name, err)
 			}
-			if am.TLS.CertFile == "" || am.TLS.KeyFile == "" {
-				return fmt.Errorf("auth_module %s type tls requires cert_file and key_file for client certificate authentication", name)
+			*f = v
+		}
+		c.AuthModules[name] = am
+	}
+	for name, t := range c.Targets {
+		t.URLs = slices.Clone(t.URLs)
+		fields := []*string{&t.URL}
+		for i := range t.URLs {
+			fields = append(fields, &t.URLs[i])
+		}
+		for _, f := range fields {
+			v, err := expandEnvRefs(*f)
+			if err != nil {
+				return fmt.Errorf("target %s: %w", name, err)
 			}
-			// Validate that other auth fields are not set when using TLS auth type
-			if am.UserPass != nil {
-				return fmt.Errorf("auth_module %s type tls cannot have userpass configuration", name)
+			*f = v
+		}
+		c.Targets[name] = t
+	}
+	return nil
+}
+
+// validate ensures every auth module has the required fields according to its type.
+func (c *Config) validate() error {
+	for name, am := range c.AuthModules {
+		if err := am.Validate(name); err != nil {
+			return err
+		}
+	}
+
+	for name, t := range c.Targets {
+		if (t.URL == "") == (len(t.URLs) == 0) {
+			return fmt.Errorf("target %s requires either url or urls", name)
+		}
+		for _, endpoint := range t.Endpoints() {
+			u, err := url.Parse(endpoint)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return fmt.Errorf("target %s has invalid url %s", name, endpoint)
 			}
-			if am.APIKey != "" {
-				return fmt.Errorf("auth_module %s type tls cannot have apikey", name)
+		}
+		if t.AuthModule != "" {
+			if _, ok := c.AuthModules[t.AuthModule]; !ok {
+				return fmt.Errorf("target %s references unknown auth_module %s", name, t.AuthModule)
 			}
-			if am.AWS != nil {
-				return fmt.Errorf("auth_module %s type tls cannot have aws configuration", name)
+		}
+		if len(t.Node) > 0 {
+			if _, err := ParseNodeSelector(t.Node); err != nil {
+				return fmt.Errorf("target %s: %w", name, err)
 			}
-		default:
-			return fmt.Errorf("auth_module %s has unsupported type %s", name, am.Type)
 		}
-
-		// Validate TLS configuration (optional for all auth types, provides transport security)
-		if am.TLS != nil {
-			// For cert-based auth (type: tls), cert and key are required
-			// For other auth types, TLS config is optional and used for transport security
-			if strings.ToLower(am.Type) != "tls" {
-				// For non-TLS auth types, if cert/key are provided, both must be present
-				if (am.TLS.CertFile != "") != (am.TLS.KeyFile != "") {
-					return fmt.Errorf("auth_module %s: if providing client certificate, both cert_file and key_file must be specified", name)
-				}
+		if t.Timeout < 0 {
+			return fmt.Errorf("target %s: timeout must not be negative", name)
+		}
+		if t.TLS != nil {
+			if (t.TLS.CertFile != "") != (t.TLS.KeyFile != "") {
+				return fmt.Errorf("target %s: if providing client certificate, both cert_file and key_file must be specified", name)
 			}
-
-			// Validate file accessibility
 			for fileType, path := range map[string]string{
-				"ca_file":   am.TLS.CAFile,
-				"cert_file": am.TLS.CertFile,
-				"key_file":  am.TLS.KeyFile,
+				"ca_file":   t.TLS.CAFile,
+				"cert_file": t.TLS.CertFile,
+				"key_file":  t.TLS.KeyFile,
 			} {
 				if path == "" {
 					continue
 				}
 				if _, err := os.Stat(path); err != nil {
-					return fmt.Errorf("auth_module %s: %s '%s' not accessible: %w", name, fileType, path, err)
+					return fmt.Errorf("target %s: %s '%s' not accessible: %w", name, fileType, path, err)
 				}
 			}
 		}
 	}
+
+	for name, cc := range c.Collectors {
+		if cc.Timeout < 0 || cc.MinInterval < 0 {
+			return fmt.Errorf("collector %s: timeout and min_interval must not be negative", name)
+		}
+		switch cc.Mode {
+		case "", "sync":
+		case "background":
+			if cc.MinInterval == 0 {
+				return fmt.Errorf("collector %s: mode background requires min_interval", name)
+			}
+		default:
+			return fmt.Errorf("collector %s has unsupported mode %s", name, cc.Mode)
+		}
+	}
 	return nil
 }
 
-// LoadConfig reads, parses, and validates the YAML config file.
+// LoadConfig reads, parses, and validates the YAML config file. ${VAR}
+// references in credentials, secret file paths and target URLs are replaced
+// with the value of the environment variable VAR.
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -130,6 +447,9 @@ func LoadConfig(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	if err := cfg.expandEnv(); err != nil {
+		return nil, err
+	}
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
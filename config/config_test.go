@@ -15,6 +15,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -35,6 +38,8 @@ func TestLoadConfigPositiveVariants(t *testing.T) {
 	ca := mustTempFile(t)
 	cert := mustTempFile(t)
 	key := mustTempFile(t)
+	secret := mustTempFile(t)
+	t.Setenv("ES_EXPORTER_TEST_APIKEY", "ZXhhbXBsZQ==")
 
 	positive := []struct {
 		name string
@@ -92,6 +97,84 @@ func TestLoadConfigPositiveVariants(t *testing.T) {
       ca_file: ` + ca + `
       cert_file: ` + cert + `
       key_file: ` + key + ``,
+	}, {
+		"collectors",
+		`collectors:
+  snapshots:
+    timeout: 30s
+    min_interval: 5m
+    mode: background
+  nodes:
+    timeout: 3s`,
+	}, {
+		"userpass-password-file",
+		`auth_modules:
+  basic:
+    type: userpass
+    userpass:
+      username: u
+      password_file: ` + secret,
+	}, {
+		"apikey-file",
+		`auth_modules:
+  key:
+    type: apikey
+    apikey_file: ` + secret,
+	}, {
+		"bearer",
+		`auth_modules:
+  cloud:
+    type: bearer
+    bearer:
+      token: abc`,
+	}, {
+		"bearer-file",
+		`auth_modules:
+  cloud:
+    type: bearer
+    bearer:
+      token_file: ` + secret,
+	}, {
+		"oauth2",
+		`auth_modules:
+  oidc:
+    type: oauth2
+    oauth2:
+      client_id: exporter
+      client_secret_file: ` + secret + `
+      token_url: https://idp.example.com/oauth2/token
+      scopes: [elasticsearch]`,
+	}, {
+		"env",
+		`auth_modules:
+  key:
+    type: apikey
+    apikey: ${ES_EXPORTER_TEST_APIKEY}`,
+	}, {
+		"targets",
+		`auth_modules:
+  basic:
+    type: userpass
+    userpass:
+      username: u
+      password: p
+targets:
+  prod-logs:
+    url: https://logs.example.com:9200
+    auth_module: basic
+    collectors: [cluster-health, nodes]
+    node: _all
+    timeout: 20s
+  replicated:
+    urls: [https://es-1:9200, https://es-2:9200]
+    node: [master:true, attr.zone:us-east-1a]
+  small:
+    url: http://small.example.com:9200
+    collectors: [cluster-health, indices, shards]
+    shard_level: true
+    tls:
+      ca_file: ` + ca + `
+      insecure_skip_verify: true`,
 	}}
 
 	for _, c := range positive {
@@ -169,6 +252,113 @@ func TestLoadConfigNegativeVariants(t *testing.T) {
 		`auth_modules:
   bad:
     type: foobar`,
+	}, {
+		"collectorUnsupportedMode",
+		`collectors:
+  snapshots:
+    mode: async`,
+	}, {
+		"collectorBackgroundWithoutInterval",
+		`collectors:
+  snapshots:
+    mode: background`,
+	}, {
+		"collectorNegativeTimeout",
+		`collectors:
+  snapshots:
+    timeout: -1s`,
+	}, {
+		"userpassPasswordAndFile",
+		`auth_modules:
+  bad:
+    type: userpass
+    userpass: {username: u, password: p, password_file: ` + cert + `}`,
+	}, {
+		"userpassMissingPasswordFile",
+		`auth_modules:
+  bad:
+    type: userpass
+    userpass: {username: u, password_file: /does/not/exist}`,
+	}, {
+		"apikeyMissingFile",
+		`auth_modules:
+  bad:
+    type: apikey
+    apikey_file: /does/not/exist`,
+	}, {
+		"bearerMissingToken",
+		`auth_modules:
+  bad:
+    type: bearer`,
+	}, {
+		"bearerMissingFile",
+		`auth_modules:
+  bad:
+    type: bearer
+    bearer: {token_file: /does/not/exist}`,
+	}, {
+		"oauth2MissingSecret",
+		`auth_modules:
+  bad:
+    type: oauth2
+    oauth2: {client_id: c, token_url: https://idp.example.com/token}`,
+	}, {
+		"oauth2InvalidTokenURL",
+		`auth_modules:
+  bad:
+    type: oauth2
+    oauth2: {client_id: c, client_secret: s, token_url: idp.example.com}`,
+	}, {
+		"unsetEnv",
+		`auth_modules:
+  bad:
+    type: apikey
+    apikey: ${ES_EXPORTER_TEST_UNSET}`,
+	}, {
+		"targetMissingURL",
+		`targets:
+  bad:
+    auth_module: basic`,
+	}, {
+		"targetURLAndURLs",
+		`targets:
+  bad:
+    url: http://es-1:9200
+    urls: [http://es-2:9200]`,
+	}, {
+		"targetInvalidURLs",
+		`targets:
+  bad:
+    urls: [http://es-1:9200, es-2:9200]`,
+	}, {
+		"targetInvalidScheme",
+		`targets:
+  bad:
+    url: ftp://example.com`,
+	}, {
+		"targetUnknownAuthModule",
+		`targets:
+  bad:
+    url: http://localhost:9200
+    auth_module: missing`,
+	}, {
+		"targetNegativeTimeout",
+		`targets:
+  bad:
+    url: http://localhost:9200
+    timeout: -5s`,
+	}, {
+		"targetInvalidNodeSelector",
+		`targets:
+  bad:
+    url: http://localhost:9200
+    node: _nodes/../_cluster`,
+	}, {
+		"targetEmptyNodeFilter",
+		`targets:
+  bad:
+    url: http://localhost:9200
+    node: "master:true,,data:true"`,
 	}}
 
 	for _, c := range negative {
@@ -181,3 +371,108 @@ func TestLoadConfigNegativeVariants(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandEnvRefs(t *testing.T) {
+	t.Setenv("ES_EXPORTER_TEST_USER", "metrics")
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "${ES_EXPORTER_TEST_USER}", want: "metrics"},
+		{in: "prefix-${ES_EXPORTER_TEST_USER}-suffix", want: "prefix-metrics-suffix"},
+		{in: "$${ES_EXPORTER_TEST_USER}", want: "${ES_EXPORTER_TEST_USER}"},
+		{in: "pa$${ss", want: "pa${ss"},
+		{in: "$${ES_EXPORTER_TEST_UNSET}", want: "${ES_EXPORTER_TEST_UNSET}"},
+		{in: "no$refs{here}", want: "no$refs{here}"},
+		{in: "${ES_EXPORTER_TEST_UNSET}", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := expandEnvRefs(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("expandEnvRefs(%q): unexpected error %v", tt.in, err)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("expandEnvRefs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigSecrets(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ES_EXPORTER_TEST_USER", "metrics")
+	t.Setenv("ES_EXPORTER_TEST_APIKEY", "ZXhhbXBsZQ==")
+
+	cfgFile := filepath.Join(dir, "config.yml")
+	content := `auth_modules:
+  basic:
+    type: userpass
+    userpass:
+      username: ${ES_EXPORTER_TEST_USER}
+      password_file: ` + passwordFile + `
+  key:
+    type: apikey
+    apikey: ${ES_EXPORTER_TEST_APIKEY}`
+	if err := os.WriteFile(cfgFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(cfgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	basic := cfg.AuthModules["basic"]
+	if basic.UserPass.Username != "metrics" {
+		t.Errorf("expected username from environment, got %q", basic.UserPass.Username)
+	}
+	password, err := basic.UserPass.ResolvePassword()
+	if err != nil || password != "s3cr3t" {
+		t.Errorf("expected password from file, got %q (err: %v)", password, err)
+	}
+	key := cfg.AuthModules["key"]
+	if apiKey, _ := key.ResolveAPIKey(); apiKey != "ZXhhbXBsZQ==" {
+		t.Errorf("expected API key from environment, got %q", apiKey)
+	}
+
+	// rotated secrets are picked up without reloading the config
+	if err := os.WriteFile(passwordFile, []byte("r0t4t3d"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if password, _ := basic.UserPass.ResolvePassword(); password != "r0t4t3d" {
+		t.Errorf("expected rotated password, got %q", password)
+	}
+
+	// errors name the missing file
+	missing := filepath.Join(dir, "missing")
+	if err := os.WriteFile(cfgFile, []byte(`auth_modules:
+  key:
+    type: apikey
+    apikey_file: `+missing), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(cfgFile); err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("expected error naming %s, got %v", missing, err)
+	}
+}
+
+func TestParseNodeSelector(t *testing.T) {
+	got, err := ParseNodeSelector([]string{"master:true, data_hot:true", "attr.zone:us-east-1a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (NodeSelector{"master:true", "data_hot:true", "attr.zone:us-east-1a"}); !slices.Equal(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	for _, invalid := range []string{"", "..", "a/b", ":true", "master:"} {
+		if _, err := ParseNodeSelector([]string{invalid}); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
@@ -44,49 +44,27 @@ import (
 
 const name = "elasticsearch_exporter"
 
-type transportWithAPIKey struct {
-	underlyingTransport http.RoundTripper
-	apiKey              string
-}
-
-func (t *transportWithAPIKey) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Add("Authorization", fmt.Sprintf("ApiKey %s", t.apiKey))
-	return t.underlyingTransport.RoundTrip(req)
-}
-
 func main() {
 	var (
 		metricsPath = kingpin.Flag("web.telemetry-path",
 			"Path under which to expose metrics.").
 			Default("/metrics").String()
 		toolkitFlags = webflag.AddFlags(kingpin.CommandLine, ":9114")
-		esURI        = kingpin.Flag("es.uri",
-			"HTTP API address of an Elasticsearch node.").
-			Default("").String()
+		esURIs       = kingpin.Flag("es.uri",
+			"HTTP API address of an Elasticsearch node. Repeat to fail over between several nodes of the same cluster.").
+			Strings()
+		esLoadBalancing = kingpin.Flag("es.load-balancing",
+			"How requests are spread over several es.uri or target urls: failover or round-robin.").
+			Default(roundtripper.StrategyFailover).Enum(roundtripper.StrategyFailover, roundtripper.StrategyRoundRobin)
+		esEndpointRetryInterval = kingpin.Flag("es.endpoint-retry-interval",
+			"How long a failed Elasticsearch endpoint is skipped before it is tried again.").
+			Default("30s").Duration()
 		esTimeout = kingpin.Flag("es.timeout",
 			"Timeout for trying to get stats from Elasticsearch.").
 			Default("5s").Duration()
-		esAllNodes = kingpin.Flag("es.all",
-			"Export stats for all nodes in the cluster. If used, this flag will override the flag es.node.").
-			Default("false").Bool()
-		esNode = kingpin.Flag("es.node",
-			"Node's name of which metrics should be exposed.").
-			Default("_local").String()
-		esExportIndices = kingpin.Flag("es.indices",
-			"Export stats for indices in the cluster.").
-			Default("false").Bool()
-		esExportIndicesSettings = kingpin.Flag("es.indices_settings",
-			"Export stats for settings of all indices of the cluster.").
-			Default("false").Bool()
-		esExportIndicesMappings = kingpin.Flag("es.indices_mappings",
-			"Export stats for mappings of all indices of the cluster.").
-			Default("false").Bool()
-		esExportIndexAliases = kingpin.Flag("es.aliases",
-			"Export informational alias metrics.").
-			Default("true").Bool()
-		esExportShards = kingpin.Flag("es.shards",
-			"Export stats for shards in the cluster (implies --es.indices).").
-			Default("false").Bool()
+		timeoutOffset = kingpin.Flag("web.timeout-offset",
+			"Offset to subtract from the scrape timeout announced by Prometheus in the X-Prometheus-Scrape-Timeout-Seconds header.").
+			Default("0.5s").Duration()
 		esClusterInfoInterval = kingpin.Flag("es.clusterinfo.interval",
 			"Cluster info update interval for the cluster label").
 			Default("5m").Duration()
@@ -111,7 +89,10 @@ func main() {
 		awsRoleArn = kingpin.Flag("aws.role-arn",
 			"Role ARN of an IAM role to assume.").
 			Default("").String()
-		configFile = kingpin.Flag("config.file", "Path to YAML configuration file.").Default("").String()
+		configFile   = kingpin.Flag("config.file", "Path to YAML configuration file.").Default("").String()
+		esAuthModule = kingpin.Flag("es.auth-module",
+			"Name of an auth module from config.file used to connect to es.uri. Changes of the auth module apply on config reload, except for its options.").
+			Default("").String()
 	)
 
 	promslogConfig := &promslog.Config{}
@@ -124,7 +105,7 @@ func main() {
 	var cfg *config.Config
 	if *configFile != "" {
 		var cfgErr error
-		cfg, cfgErr = config.LoadConfig(*configFile)
+		cfg, cfgErr = loadConfig(*configFile)
 		if cfgErr != nil {
 			// At this stage logger not yet created; fallback to stderr
 			fmt.Fprintf(os.Stderr, "failed to load config file: %v\n", cfgErr)
@@ -132,6 +113,11 @@ func main() {
 		}
 	}
 
+	var collectorConfigs map[string]config.CollectorConfig
+	if cfg != nil {
+		collectorConfigs = cfg.Collectors
+	}
+
 	var w io.Writer
 	switch strings.ToLower(*logOutput) {
 	case "stderr":
@@ -151,101 +137,127 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
-	if *esURI != "" {
-		esURL, err := url.Parse(*esURI)
-		if err != nil {
-			logger.Error("failed to parse es.uri", "err", err)
-			os.Exit(1)
-		}
-
-		esUsername := os.Getenv("ES_USERNAME")
-		esPassword := os.Getenv("ES_PASSWORD")
-
-		if esUsername != "" && esPassword != "" {
-			esURL.User = url.UserPassword(esUsername, esPassword)
-		}
-
-		// returns nil if not provided and falls back to simple TCP.
-		tlsConfig := createTLSConfig(*esCA, *esClientCert, *esClientPrivateKey, *esInsecureSkipVerify)
-
-		var httpTransport http.RoundTripper
-
-		httpTransport = &http.Transport{
-			TLSClientConfig:   tlsConfig,
-			Proxy:             http.ProxyFromEnvironment,
-			ForceAttemptHTTP2: true,
-		}
+	// The config file is reloaded on SIGHUP and POST /-/reload. Reloads apply
+	// to /probe; the single-target exporter only picks up changes of its
+	// --es.auth-module and otherwise keeps its startup settings.
+	var reloader *configReloader
+	if *configFile != "" {
+		reloader = newConfigReloader(*configFile, cfg, logger)
+		prometheus.MustRegister(reloader)
+		go reloader.watchSignals(ctx)
+		http.Handle("/-/reload", reloader)
+	}
 
-		esAPIKey := os.Getenv("ES_API_KEY")
+	// TLS settings from the command line, used by /metrics and as defaults for /probe.
+	flagTLS := &config.TLSConfig{
+		CAFile:             *esCA,
+		CertFile:           *esClientCert,
+		KeyFile:            *esClientPrivateKey,
+		InsecureSkipVerify: *esInsecureSkipVerify,
+	}
 
-		if esAPIKey != "" {
-			httpTransport = &transportWithAPIKey{
-				underlyingTransport: httpTransport,
-				apiKey:              esAPIKey,
+	var exporter *collector.ElasticsearchCollector
+	if len(*esURIs) > 0 && (*esURIs)[0] != "" {
+		var endpoints []*url.URL
+		for _, esURI := range *esURIs {
+			u, err := url.Parse(esURI)
+			if err != nil {
+				logger.Error("failed to parse es.uri", "err", err)
+				os.Exit(1)
 			}
+			endpoints = append(endpoints, u)
 		}
-
-		httpClient := &http.Client{
-			Timeout:   *esTimeout,
-			Transport: httpTransport,
+		pool, err := roundtripper.NewEndpointPool(endpoints, *esLoadBalancing, *esEndpointRetryInterval)
+		if err != nil {
+			logger.Error("failed to create endpoint pool", "err", err)
+			os.Exit(1)
 		}
-
-		if *awsRegion != "" {
-			var err error
-			httpClient.Transport, err = roundtripper.NewAWSSigningTransport(httpTransport, *awsRegion, *awsRoleArn, logger)
+		prometheus.MustRegister(pool)
+
+		// Requests are built against the first endpoint and rewritten to the
+		// selected one by the endpoint pool's transport.
+		esURL := endpoints[0]
+
+		// Authentication comes either from an auth module of the config file
+		// or from the legacy environment variables and flags.
+		tlsSettings := []*config.TLSConfig{flagTLS}
+		var authModules []*config.AuthModule
+		if *esAuthModule != "" {
+			if cfg == nil {
+				logger.Error("es.auth-module requires config.file")
+				os.Exit(1)
+			}
+			am, ok := cfg.AuthModules[*esAuthModule]
+			if !ok {
+				logger.Error("auth module not found in config file", "auth_module", *esAuthModule)
+				os.Exit(1)
+			}
+			tlsSettings = append(tlsSettings, am.TLS)
+			authModules = []*config.AuthModule{&am}
+			applyAuthModuleOptions(esURL, &am)
+		} else {
+			esUsername := os.Getenv("ES_USERNAME")
+			esPassword := os.Getenv("ES_PASSWORD")
+
+			if esUsername != "" && esPassword != "" {
+				esURL.User = url.UserPassword(esUsername, esPassword)
+			}
+			authModules, err = legacyAuthModules(*awsRegion, *awsRoleArn)
 			if err != nil {
-				logger.Error("failed to create AWS transport", "err", err)
+				logger.Error("invalid authentication settings", "err", err)
 				os.Exit(1)
 			}
 		}
 
+		transportMetrics := roundtripper.NewTransportMetrics()
+		prometheus.MustRegister(transportMetrics)
+
+		clientCfg := clientConfig{
+			tls:         tlsSettings,
+			authModules: authModules,
+			endpoints:   pool,
+			timeout:     *esTimeout,
+			metrics:     transportMetrics,
+		}
+		client, baseTransport, err := newHTTPClient(clientCfg, logger)
+		if err != nil {
+			logger.Error("failed to create HTTP client", "err", err)
+			os.Exit(1)
+		}
+		clientTransport := &swappableTransport{}
+		clientTransport.swap(client.Transport, baseTransport)
+		httpClient := &http.Client{Timeout: *esTimeout, Transport: clientTransport}
+
+		// A reload that changes the auth module rebuilds the client. The
+		// options of the auth module are part of esURL and keep their startup
+		// values.
+		if *esAuthModule != "" {
+			reloader.OnReload(authModuleReloader(*esAuthModule, cfg.AuthModules[*esAuthModule], clientCfg, clientTransport, logger))
+		}
+
 		// This should replace the below cluster info retriever in the future.
 		infoRetriever := cluster.NewInfoProvider(logger, httpClient, esURL, *esClusterInfoInterval)
 
 		// create the exporter
-		exporter, err := collector.NewElasticsearchCollector(
+		exporter, err = collector.NewElasticsearchCollector(
 			logger,
 			[]string{},
 			collector.WithElasticsearchURL(esURL),
 			collector.WithHTTPClient(httpClient),
 			collector.WithClusterInfoProvider(infoRetriever),
+			collector.WithCollectorConfigs(collectorConfigs),
 		)
 		if err != nil {
 			logger.Error("failed to create Elasticsearch collector", "err", err)
 			os.Exit(1)
 		}
-		prometheus.MustRegister(exporter)
+		// start the collectors running in background mode
+		exporter.Run(ctx)
 
 		// TODO(@sysadmind): Remove this when we have a better way to get the cluster name to down stream collectors.
 		// cluster info retriever
 		clusterInfoRetriever := clusterinfo.New(logger, httpClient, esURL, *esClusterInfoInterval)
 
-		prometheus.MustRegister(collector.NewClusterHealth(logger, httpClient, esURL))
-		prometheus.MustRegister(collector.NewNodes(logger, httpClient, esURL, *esAllNodes, *esNode))
-
-		if *esExportIndices || *esExportShards {
-			sC := collector.NewShards(logger, httpClient, esURL)
-			prometheus.MustRegister(sC)
-			iC := collector.NewIndices(logger, httpClient, esURL, *esExportShards, *esExportIndexAliases)
-			prometheus.MustRegister(iC)
-			if registerErr := clusterInfoRetriever.RegisterConsumer(iC); registerErr != nil {
-				logger.Error("failed to register indices collector in cluster info")
-				os.Exit(1)
-			}
-			if registerErr := clusterInfoRetriever.RegisterConsumer(sC); registerErr != nil {
-				logger.Error("failed to register shards collector in cluster info")
-				os.Exit(1)
-			}
-		}
-
-		if *esExportIndicesSettings {
-			prometheus.MustRegister(collector.NewIndicesSettings(logger, httpClient, esURL))
-		}
-
-		if *esExportIndicesMappings {
-			prometheus.MustRegister(collector.NewIndicesMappings(logger, httpClient, esURL))
-		}
-
 		// start the cluster info retriever
 		switch runErr := clusterInfoRetriever.Run(ctx); runErr {
 		case nil:
@@ -261,11 +273,41 @@ func main() {
 		prometheus.MustRegister(clusterInfoRetriever)
 	}
 
-	http.HandleFunc(*metricsPath, func(w http.ResponseWriter, r *http.Request) {
+	// The handler metrics are registered once; only the registry of the
+	// Elasticsearch collector is created per scrape.
+	http.Handle(*metricsPath, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// /metrics endpoint is reserved for single-target mode only.
 		// For per-scrape overrides use the dedicated /probe endpoint.
-		promhttp.Handler().ServeHTTP(w, r)
-	})
+		if exporter == nil {
+			promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel, err := scrapeContext(r, *timeoutOffset)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer cancel()
+
+		exp := exporter
+		if filters := r.URL.Query()["collect[]"]; len(filters) > 0 {
+			exp, err = exporter.Filter(filters)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		// The Elasticsearch collector is registered per scrape so that its
+		// requests are bound to the scrape context.
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(exp.WithContext(ctx))
+		// The default gatherer is gathered last so that the HTTP client
+		// metrics include the requests of this scrape.
+		gatherers := prometheus.Gatherers{reg, prometheus.DefaultGatherer}
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})))
 
 	if *metricsPath != "/" && *metricsPath != "" {
 		landingConfig := web.LandingConfig{
@@ -295,88 +337,57 @@ func main() {
 	// probe endpoint
 	http.HandleFunc("/probe", func(w http.ResponseWriter, r *http.Request) {
 		origQuery := r.URL.Query()
-		targetStr, am, valErr := validateProbeParams(cfg, origQuery)
+		probeCfg := cfg
+		if reloader != nil {
+			probeCfg = reloader.Config()
+		}
+		var probeCollectorConfigs map[string]config.CollectorConfig
+		if probeCfg != nil {
+			probeCollectorConfigs = probeCfg.Collectors
+		}
+		targetStr, am, target, valErr := resolveProbeTarget(probeCfg, origQuery)
 		if valErr != nil {
 			http.Error(w, valErr.Error(), http.StatusBadRequest)
 			return
 		}
+		ctx, cancel, err := scrapeContext(r, *timeoutOffset)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer cancel()
 		targetURL, _ := url.Parse(targetStr)
+		applyAuthModuleOptions(targetURL, am)
+
+		// Build a dedicated HTTP client for this probe request. TLS settings
+		// of the auth module and the named target override the flags.
+		probeClientConfig := clientConfig{
+			tls:               []*config.TLSConfig{flagTLS},
+			timeout:           *esTimeout,
+			disableKeepAlives: true,
+			// Requests of this probe are reported in the probe response.
+			metrics: roundtripper.NewTransportMetrics(),
+		}
 		if am != nil {
-			// Apply userpass credentials only if the module type is explicitly set to userpass.
-			if strings.EqualFold(am.Type, "userpass") && am.UserPass != nil {
-				targetURL.User = url.UserPassword(am.UserPass.Username, am.UserPass.Password)
-			}
-			if len(am.Options) > 0 {
-				q := targetURL.Query()
-				for k, v := range am.Options {
-					q.Set(k, v)
-				}
-				targetURL.RawQuery = q.Encode()
-			}
+			probeClientConfig.tls = append(probeClientConfig.tls, am.TLS)
+			probeClientConfig.authModules = []*config.AuthModule{am}
 		}
-
-		// Build a dedicated HTTP client for this probe request (reuse TLS opts, timeout, etc.).
-		pemCA := *esCA
-		pemCert := *esClientCert
-		pemKey := *esClientPrivateKey
-		insecure := *esInsecureSkipVerify
-
-		// Apply TLS configuration from auth module if provided (for transport security)
-		// This matches single-target behavior where TLS settings are always applied
-		if am != nil && am.TLS != nil {
-			// Override with module-specific TLS settings
-			if am.TLS.CAFile != "" {
-				pemCA = am.TLS.CAFile
-			}
-			if am.TLS.CertFile != "" {
-				pemCert = am.TLS.CertFile
-			}
-			if am.TLS.KeyFile != "" {
-				pemKey = am.TLS.KeyFile
-			}
-			if am.TLS.InsecureSkipVerify {
-				insecure = true
+		if target != nil {
+			probeClientConfig.tls = append(probeClientConfig.tls, target.TLS)
+			if target.Timeout > 0 {
+				probeClientConfig.timeout = target.Timeout
 			}
-		}
-		tlsCfg := createTLSConfig(pemCA, pemCert, pemKey, insecure)
-		baseTransport := &http.Transport{
-			TLSClientConfig:   tlsCfg,
-			Proxy:             http.ProxyFromEnvironment,
-			ForceAttemptHTTP2: true,
-			DisableKeepAlives: true,
-		}
-		var transport http.RoundTripper = baseTransport
-
-		// inject authentication based on auth_module type
-		if am != nil {
-			switch strings.ToLower(am.Type) {
-			case "apikey":
-				if am.APIKey != "" {
-					transport = &transportWithAPIKey{
-						underlyingTransport: transport,
-						apiKey:              am.APIKey,
-					}
-				}
-			case "aws":
-				var region string
-				if am.AWS.Region != "" {
-					region = am.AWS.Region
-				}
-				var err error
-				transport, err = roundtripper.NewAWSSigningTransport(transport, region, am.AWS.RoleARN, logger)
-				if err != nil {
-					http.Error(w, "failed to create AWS signing transport", http.StatusInternalServerError)
-					return
-				}
-			case "tls":
-				// No additional auth wrapper needed - client certificates in TLS config handle authentication
-			case "userpass":
-				// Already handled above by setting targetURL.User
+			probeClientConfig.endpoints, err = targetEndpointPool(origQuery.Get("target_name"), target.Endpoints(), *esLoadBalancing, *esEndpointRetryInterval)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("failed to create endpoint pool: %s", err), http.StatusInternalServerError)
+				return
 			}
 		}
-		probeClient := &http.Client{
-			Timeout:   *esTimeout,
-			Transport: transport,
+		probeClient, baseTransport, err := newHTTPClient(probeClientConfig, logger)
+		if err != nil {
+			logger.Error("failed to create HTTP client", "err", err)
+			http.Error(w, "failed to create HTTP client", http.StatusInternalServerError)
+			return
 		}
 		// Close idle connections when handler completes to prevent resource leaks.
 		defer baseTransport.CloseIdleConnections()
@@ -391,36 +402,45 @@ func main() {
 		// background goroutine and is safe to discard when the handler returns.
 		infoProvider := cluster.NewInfoProvider(logger, probeClient, targetURL, *esClusterInfoInterval)
 
-		// Core exporter collector
-		exp, err := collector.NewElasticsearchCollector(
-			logger,
-			[]string{},
+		opts := []collector.Option{
 			collector.WithElasticsearchURL(targetURL),
 			collector.WithHTTPClient(probeClient),
 			collector.WithClusterInfoProvider(infoProvider),
-		)
-		if err != nil {
-			http.Error(w, "failed to create exporter", http.StatusInternalServerError)
-			return
+			collector.WithCollectorConfigs(probeCollectorConfigs),
+			collector.WithRunnerStates(runnerStatesFor(probeStateKey(origQuery), time.Now())),
 		}
-		reg.MustRegister(exp)
-		// Basic additional collectors – reuse global CLI flags
-		reg.MustRegister(collector.NewClusterHealth(logger, probeClient, targetURL))
-		reg.MustRegister(collector.NewNodes(logger, probeClient, targetURL, *esAllNodes, *esNode))
-		if *esExportIndices || *esExportShards {
-			shardsC := collector.NewShards(logger, probeClient, targetURL)
-			indicesC := collector.NewIndices(logger, probeClient, targetURL, *esExportShards, *esExportIndexAliases)
-			reg.MustRegister(shardsC)
-			reg.MustRegister(indicesC)
+		if target != nil {
+			if len(target.Collectors) > 0 {
+				opts = append(opts, collector.WithCollectors(target.Collectors))
+			}
+			if target.ShardLevel != nil {
+				opts = append(opts, collector.WithShardLevel(*target.ShardLevel))
+			}
+			if len(target.Node) > 0 {
+				opts = append(opts, collector.WithNodeSelector(target.Node))
+			}
 		}
-		if *esExportIndicesSettings {
-			reg.MustRegister(collector.NewIndicesSettings(logger, probeClient, targetURL))
+
+		// Core exporter collector. It is created per probe, but shares the
+		// cached metrics of its collectors with the earlier probes of the
+		// same cluster.
+		exp, err := collector.NewElasticsearchCollector(logger, origQuery["collect[]"], opts...)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to create exporter: %s", err), http.StatusBadRequest)
+			return
 		}
-		if *esExportIndicesMappings {
-			reg.MustRegister(collector.NewIndicesMappings(logger, probeClient, targetURL))
+		reg.MustRegister(exp.WithContext(ctx))
+
+		// The HTTP client metrics are gathered after the exporter so that they
+		// include all requests of this probe.
+		transportReg := prometheus.NewRegistry()
+		transportReg.MustRegister(probeClientConfig.metrics)
+		if probeClientConfig.endpoints != nil {
+			transportReg.MustRegister(probeClientConfig.endpoints)
 		}
+		gatherers := prometheus.Gatherers{reg, transportReg}
 
-		promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
 	})
 
 	server := &http.Server{}
@@ -180,7 +180,7 @@ func (r *Retriever) Run(ctx context.Context) error {
 				return
 			case <-r.sync:
 				r.logger.Info("providing consumers with updated cluster info label")
-				res, err := r.fetchAndDecodeClusterInfo()
+				res, err := r.fetchAndDecodeClusterInfo(ctx)
 				if err != nil {
 					r.logger.Error(
 						"failed to retrieve cluster info from ES",
@@ -249,12 +249,17 @@ func (r *Retriever) Run(ctx context.Context) error {
 	}
 }
 
-func (r *Retriever) fetchAndDecodeClusterInfo() (*Response, error) {
+func (r *Retriever) fetchAndDecodeClusterInfo(ctx context.Context) (*Response, error) {
 	var response *Response
 	u := *r.url
 	u.Path = path.Join(r.url.Path, "/")
 
-	res, err := r.client.Get(u.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.client.Do(req)
 	if err != nil {
 		r.logger.Error(
 			"failed to get cluster info",
@@ -170,7 +170,7 @@ func TestRetriever_fetchAndDecodeClusterInfo(t *testing.T) {
 		t.Skipf("internal test error: %s", err)
 	}
 	retriever := New(promslog.NewNopLogger(), mockES.Client(), u, 0)
-	ci, err := retriever.fetchAndDecodeClusterInfo()
+	ci, err := retriever.fetchAndDecodeClusterInfo(context.Background())
 	if err != nil {
 		t.Fatalf("failed to retrieve cluster info: %s", err)
 	}
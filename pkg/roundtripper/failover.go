@@ -0,0 +1,302 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundtripper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// StrategyFailover sends all requests to the first healthy endpoint.
+	StrategyFailover = "failover"
+	// StrategyRoundRobin spreads requests over all healthy endpoints.
+	StrategyRoundRobin = "round-robin"
+)
+
+var endpointUpDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "endpoint_up"),
+	"Whether the last request to an Elasticsearch endpoint succeeded. Endpoints are not probed in between requests.",
+	[]string{"endpoint"},
+	nil,
+)
+
+// EndpointPool tracks the health of equivalent Elasticsearch endpoints, e.g.
+// several coordinating nodes of one cluster. An endpoint is marked down when
+// a request to it fails and is only tried again after the retry interval,
+// unless no healthy endpoint is left. It implements prometheus.Collector,
+// reporting the outcome of the last request to each endpoint.
+type EndpointPool struct {
+	endpoints  []*endpoint
+	strategy   string
+	retryAfter time.Duration
+	next       atomic.Uint64
+}
+
+type endpoint struct {
+	url *url.URL
+
+	mu        sync.Mutex
+	downUntil time.Time
+	failed    bool
+}
+
+// up returns whether requests should be sent to the endpoint, which is the
+// case unless a request failed within the retry interval.
+func (e *endpoint) up(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.downUntil)
+}
+
+// lastFailed returns whether the last request to the endpoint failed.
+func (e *endpoint) lastFailed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.failed
+}
+
+func (e *endpoint) markUp() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.downUntil = time.Time{}
+	e.failed = false
+}
+
+func (e *endpoint) markDown(until time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.downUntil = until
+	e.failed = true
+}
+
+// label returns the endpoint URL without credentials.
+func (e *endpoint) label() string {
+	u := *e.url
+	u.User = nil
+	return u.String()
+}
+
+// NewEndpointPool creates a pool of the given endpoints, which must only differ
+// in scheme, host and path prefix. Requests keep the credentials of their URL
+// on every endpoint, so the endpoints must not embed different credentials.
+func NewEndpointPool(endpoints []*url.URL, strategy string, retryAfter time.Duration) (*EndpointPool, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no endpoints given")
+	}
+	for _, u := range endpoints[1:] {
+		if u.User.String() != endpoints[0].User.String() {
+			return nil, fmt.Errorf("endpoint %s has different credentials than %s", u.Redacted(), endpoints[0].Redacted())
+		}
+	}
+	if strategy != StrategyFailover && strategy != StrategyRoundRobin {
+		return nil, fmt.Errorf("unsupported strategy %s", strategy)
+	}
+	p := &EndpointPool{strategy: strategy, retryAfter: retryAfter}
+	for _, u := range endpoints {
+		u := *u
+		p.endpoints = append(p.endpoints, &endpoint{url: &u})
+	}
+	return p, nil
+}
+
+// Primary returns the first endpoint. Requests are built against it and
+// rewritten to the selected endpoint by the FailoverTransport.
+func (p *EndpointPool) Primary() *url.URL {
+	return p.endpoints[0].url
+}
+
+// candidates returns the endpoints in the order they should be tried: healthy
+// endpoints according to the strategy, followed by the unhealthy ones.
+func (p *EndpointPool) candidates() []*endpoint {
+	start := 0
+	if p.strategy == StrategyRoundRobin {
+		start = int((p.next.Add(1) - 1) % uint64(len(p.endpoints)))
+	}
+
+	now := time.Now()
+	var healthy, unhealthy []*endpoint
+	for i := range p.endpoints {
+		e := p.endpoints[(start+i)%len(p.endpoints)]
+		if e.up(now) {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// rewrite returns u pointed at e instead of the primary endpoint.
+func (p *EndpointPool) rewrite(u *url.URL, e *endpoint) *url.URL {
+	r := *u
+	r.Scheme = e.url.Scheme
+	r.Host = e.url.Host
+	primaryPath := strings.TrimSuffix(p.Primary().Path, "/")
+	if rest, ok := strings.CutPrefix(u.Path, primaryPath); ok {
+		r.Path = strings.TrimSuffix(e.url.Path, "/") + rest
+		r.RawPath = ""
+	}
+	return &r
+}
+
+// Describe implements the prometheus.Collector interface.
+func (p *EndpointPool) Describe(ch chan<- *prometheus.Desc) {
+	ch <- endpointUpDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (p *EndpointPool) Collect(ch chan<- prometheus.Metric) {
+	for _, e := range p.endpoints {
+		up := 1.0
+		if e.lastFailed() {
+			up = 0
+		}
+		ch <- prometheus.MustNewConstMetric(endpointUpDesc, prometheus.GaugeValue, up, e.label())
+	}
+}
+
+// errAttemptTimeout is the cause of cancelled requests to an endpoint that
+// did not respond within its share of the request deadline.
+var errAttemptTimeout = errors.New("endpoint did not respond in time")
+
+// FailoverTransport sends each request to an endpoint of its pool, trying the
+// next endpoint if a request fails with a connection error, the endpoint is
+// unavailable (HTTP 502, 503 or 504) or it does not respond in time. If the
+// request has a deadline, each endpoint gets an equal share of the remaining
+// time to respond, except the last one, which gets all of it.
+type FailoverTransport struct {
+	t    http.RoundTripper
+	pool *EndpointPool
+}
+
+func NewFailoverTransport(transport http.RoundTripper, pool *EndpointPool) *FailoverTransport {
+	return &FailoverTransport{
+		t:    transport,
+		pool: pool,
+	}
+}
+
+type withoutFailoverKey struct{}
+
+// WithoutFailover returns a context for requests that must be sent to the
+// host of their URL, e.g. to query a specific node, even if it is an endpoint
+// of the pool.
+func WithoutFailover(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutFailoverKey{}, true)
+}
+
+func (f *FailoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if without, _ := req.Context().Value(withoutFailoverKey{}).(bool); without {
+		return f.t.RoundTrip(req)
+	}
+	if primary := f.pool.Primary(); req.URL.Scheme != primary.Scheme || req.URL.Host != primary.Host {
+		// Requests to other hosts are sent as they are.
+		return f.t.RoundTrip(req)
+	}
+
+	candidates := f.pool.candidates()
+	for i, e := range candidates {
+		last := i == len(candidates)-1
+
+		var body io.ReadCloser
+		if i > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, fmt.Errorf("cannot retry request with body on %s", e.label())
+			}
+			var err error
+			if body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		// The attempt timeout only bounds the time until the response
+		// headers arrive, the body is read within the request deadline.
+		ctx, cancel := context.WithCancelCause(req.Context())
+		var timer *time.Timer
+		if deadline, ok := req.Context().Deadline(); ok && !last {
+			timer = time.AfterFunc(time.Until(deadline)/time.Duration(len(candidates)-i), func() {
+				cancel(errAttemptTimeout)
+			})
+		}
+
+		r := req.Clone(ctx)
+		r.URL = f.pool.rewrite(req.URL, e)
+		r.Host = ""
+		if body != nil {
+			r.Body = body
+		}
+
+		resp, err := f.t.RoundTrip(r)
+		if timer != nil && !timer.Stop() && err == nil {
+			// The response arrived just as the attempt timed out.
+			_ = resp.Body.Close()
+			resp, err = nil, context.Cause(ctx)
+		}
+		if err == nil && !unavailable(resp.StatusCode) {
+			e.markUp()
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			// The scrape was cancelled or timed out, which says nothing
+			// about the health of the endpoint.
+			cancel(nil)
+			return resp, err
+		}
+		e.markDown(time.Now().Add(f.pool.retryAfter))
+		if last {
+			if resp != nil {
+				resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			} else {
+				cancel(nil)
+			}
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		cancel(nil)
+	}
+	// unreachable, the pool has at least one endpoint
+	return nil, fmt.Errorf("no endpoints available")
+}
+
+// cancelOnClose releases the context of a request when its response body is
+// closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelCauseFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel(nil)
+	return err
+}
+
+func unavailable(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
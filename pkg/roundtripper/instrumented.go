@@ -0,0 +1,169 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundtripper
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "elasticsearch_exporter"
+
+// resourceSegments are path segments that name an API resource rather than an
+// index, node or repository, and are therefore kept as is in the endpoint label.
+var resourceSegments = map[string]bool{
+	"explain":       true,
+	"health":        true,
+	"http":          true,
+	"mappings":      true,
+	"pending_tasks": true,
+	"settings":      true,
+	"shards":        true,
+	"stats":         true,
+	"status":        true,
+}
+
+// TransportMetrics holds the metrics of the requests sent through an
+// InstrumentedTransport. It implements prometheus.Collector.
+type TransportMetrics struct {
+	duration  *prometheus.HistogramVec
+	bytes     *prometheus.CounterVec
+	responses *prometheus.CounterVec
+}
+
+// NewTransportMetrics creates the metrics for an InstrumentedTransport.
+func NewTransportMetrics() *TransportMetrics {
+	return &TransportMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of requests to Elasticsearch, including reading the response body.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"endpoint"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "response_size_bytes_total",
+			Help:      "Total number of response body bytes read from Elasticsearch.",
+		}, []string{"endpoint"}),
+		responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "responses_total",
+			Help:      "Total number of responses received from Elasticsearch by status code.",
+		}, []string{"endpoint", "code"}),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (m *TransportMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.duration.Describe(ch)
+	m.bytes.Describe(ch)
+	m.responses.Describe(ch)
+}
+
+// Collect implements the prometheus.Collector interface.
+func (m *TransportMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.duration.Collect(ch)
+	m.bytes.Collect(ch)
+	m.responses.Collect(ch)
+}
+
+// InstrumentedTransport records the duration, response size and status code
+// of every request, labelled by the normalized Elasticsearch endpoint.
+type InstrumentedTransport struct {
+	t       http.RoundTripper
+	metrics *TransportMetrics
+}
+
+func NewInstrumentedTransport(transport http.RoundTripper, metrics *TransportMetrics) *InstrumentedTransport {
+	return &InstrumentedTransport{
+		t:       transport,
+		metrics: metrics,
+	}
+}
+
+func (i *InstrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := Endpoint(req.URL.Path)
+	begin := time.Now()
+
+	resp, err := i.t.RoundTrip(req)
+	if err != nil {
+		i.metrics.duration.WithLabelValues(endpoint).Observe(time.Since(begin).Seconds())
+		return nil, err
+	}
+
+	i.metrics.responses.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+	resp.Body = &instrumentedBody{
+		ReadCloser: resp.Body,
+		done: func(n int64) {
+			i.metrics.duration.WithLabelValues(endpoint).Observe(time.Since(begin).Seconds())
+			i.metrics.bytes.WithLabelValues(endpoint).Add(float64(n))
+		},
+	}
+	return resp, nil
+}
+
+// instrumentedBody counts the bytes read from a response body and reports
+// them once the body is closed.
+type instrumentedBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(n int64)
+}
+
+func (b *instrumentedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *instrumentedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.n) })
+	return err
+}
+
+// Endpoint normalizes a request path into a low cardinality endpoint name.
+// Any path prefix in front of the first API segment is dropped, and index,
+// node and repository names are replaced by "*", e.g. "/es/_nodes/n1/stats"
+// becomes "_nodes/*/stats".
+func Endpoint(path string) string {
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s == "" {
+			continue
+		}
+		if len(segments) == 0 && !strings.HasPrefix(s, "_") {
+			// Path prefix of a reverse proxy, or a request to an index.
+			continue
+		}
+		if !strings.HasPrefix(s, "_") && !resourceSegments[s] {
+			s = "*"
+		}
+		segments = append(segments, s)
+	}
+	if len(segments) == 0 {
+		return "/"
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,91 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundtripper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: "/"},
+		{path: "/", want: "/"},
+		{path: "/_cluster/health", want: "_cluster/health"},
+		{path: "/_nodes/stats", want: "_nodes/stats"},
+		{path: "/_nodes/node-1/stats", want: "_nodes/*/stats"},
+		{path: "/_all/_stats", want: "_all/_stats"},
+		{path: "/_snapshot/backups/_all", want: "_snapshot/*/_all"},
+		{path: "/_data_stream/*/_stats", want: "_data_stream/*/_stats"},
+		{path: "/es/_cat/shards", want: "_cat/shards"},
+		{path: "_cluster/settings", want: "_cluster/settings"},
+	}
+
+	for _, tt := range tests {
+		if got := Endpoint(tt.path); got != tt.want {
+			t.Errorf("Endpoint(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestInstrumentedTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_cluster/health" {
+			w.Write([]byte(`{"status":"green"}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	m := NewTransportMetrics()
+	hc := &http.Client{Transport: NewInstrumentedTransport(http.DefaultTransport, m)}
+
+	for _, p := range []string{"/_cluster/health", "/_cluster/health", "/_nodes/stats"} {
+		resp, err := hc.Get(ts.URL + p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	want := `# HELP elasticsearch_exporter_http_response_size_bytes_total Total number of response body bytes read from Elasticsearch.
+# TYPE elasticsearch_exporter_http_response_size_bytes_total counter
+elasticsearch_exporter_http_response_size_bytes_total{endpoint="_cluster/health"} 36
+elasticsearch_exporter_http_response_size_bytes_total{endpoint="_nodes/stats"} 0
+# HELP elasticsearch_exporter_http_responses_total Total number of responses received from Elasticsearch by status code.
+# TYPE elasticsearch_exporter_http_responses_total counter
+elasticsearch_exporter_http_responses_total{code="200",endpoint="_cluster/health"} 2
+elasticsearch_exporter_http_responses_total{code="403",endpoint="_nodes/stats"} 1
+`
+	err := testutil.CollectAndCompare(m, strings.NewReader(want),
+		"elasticsearch_exporter_http_response_size_bytes_total",
+		"elasticsearch_exporter_http_responses_total",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(m, "elasticsearch_exporter_http_request_duration_seconds"); n != 2 {
+		t.Fatalf("expected duration histograms for 2 endpoints, got %d", n)
+	}
+}
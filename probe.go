@@ -17,7 +17,10 @@ import (
 	"errors"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/prometheus-community/elasticsearch_exporter/collector"
 	"github.com/prometheus-community/elasticsearch_exporter/config"
 )
 
@@ -26,8 +29,42 @@ var (
 	errInvalidTarget     = errors.New("invalid target parameter")
 	errModuleNotFound    = errors.New("auth_module not found")
 	errUnsupportedModule = errors.New("unsupported auth_module type")
+	errTargetNotFound    = errors.New("target_name not found")
+	errAmbiguousTarget   = errors.New("target_name cannot be combined with target or auth_module")
 )
 
+// resolveProbeTarget resolves the probed cluster either from the named target
+// given by target_name or from the target and auth_module parameters. The
+// returned TargetConfig is nil for the latter.
+func resolveProbeTarget(cfg *config.Config, q url.Values) (string, *config.AuthModule, *config.TargetConfig, error) {
+	name := q.Get("target_name")
+	if name == "" {
+		target, am, err := validateProbeParams(cfg, q)
+		return target, am, nil, err
+	}
+	if q.Get("target") != "" || q.Get("auth_module") != "" {
+		return "", nil, nil, errAmbiguousTarget
+	}
+	if cfg == nil {
+		return "", nil, nil, errTargetNotFound
+	}
+	t, ok := cfg.Targets[name]
+	if !ok {
+		return "", nil, nil, errTargetNotFound
+	}
+
+	params := url.Values{}
+	params.Set("target", t.Endpoints()[0])
+	if t.AuthModule != "" {
+		params.Set("auth_module", t.AuthModule)
+	}
+	target, am, err := validateProbeParams(cfg, params)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return target, am, &t, nil
+}
+
 // validateProbeParams performs upfront validation of the query parameters.
 // It returns the target string (as given), the resolved AuthModule (optional), or an error.
 func validateProbeParams(cfg *config.Config, q url.Values) (string, *config.AuthModule, error) {
@@ -66,6 +103,10 @@ func validateProbeParams(cfg *config.Config, q url.Values) (string, *config.Auth
 		return target, &am, nil
 	case "apikey":
 		return target, &am, nil
+	case "bearer":
+		return target, &am, nil
+	case "oauth2":
+		return target, &am, nil
 	case "aws":
 		// Accept module even if region omitted; environment resolver can provide it.
 		return target, &am, nil
@@ -76,3 +117,48 @@ func validateProbeParams(cfg *config.Config, q url.Values) (string, *config.Auth
 		return "", nil, errUnsupportedModule
 	}
 }
+
+// probeStateTTL is how long the collector states of a probed cluster are kept
+// after its last probe.
+const probeStateTTL = time.Hour
+
+// probeRunnerStates keeps the collector states of the probed clusters, so
+// that min_interval caching applies across probes.
+var probeRunnerStates = struct {
+	sync.Mutex
+	m map[string]*probeStates
+}{m: make(map[string]*probeStates)}
+
+type probeStates struct {
+	states   *collector.RunnerStates
+	lastUsed time.Time
+}
+
+// probeStateKey identifies the probed cluster by target_name, or by the
+// target and auth_module parameters.
+func probeStateKey(q url.Values) string {
+	if name := q.Get("target_name"); name != "" {
+		return "target_name\x00" + name
+	}
+	return "target\x00" + q.Get("target") + "\x00" + q.Get("auth_module")
+}
+
+// runnerStatesFor returns the collector states of the cluster identified by
+// key, creating them on first use. States of clusters that have not been
+// probed for probeStateTTL are dropped.
+func runnerStatesFor(key string, now time.Time) *collector.RunnerStates {
+	probeRunnerStates.Lock()
+	defer probeRunnerStates.Unlock()
+	for k, ps := range probeRunnerStates.m {
+		if now.Sub(ps.lastUsed) > probeStateTTL {
+			delete(probeRunnerStates.m, k)
+		}
+	}
+	ps, ok := probeRunnerStates.m[key]
+	if !ok {
+		ps = &probeStates{states: collector.NewRunnerStates()}
+		probeRunnerStates.m[key] = ps
+	}
+	ps.lastUsed = now
+	return ps.states
+}
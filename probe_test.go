@@ -16,6 +16,7 @@ package main
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/prometheus-community/elasticsearch_exporter/config"
 )
@@ -124,3 +125,61 @@ func TestValidateProbeParams(t *testing.T) {
 		t.Fatalf("expected tls module, got %+v", am)
 	}
 }
+
+func TestResolveProbeTarget(t *testing.T) {
+	cfg := &config.Config{
+		AuthModules: map[string]config.AuthModule{
+			"basic": {Type: "userpass", UserPass: &config.UserPassConfig{Username: "u", Password: "p"}},
+		},
+		Targets: map[string]config.TargetConfig{
+			"prod-logs": {URL: "https://logs.example.com:9200", AuthModule: "basic", Collectors: []string{"nodes"}},
+		},
+	}
+
+	vals := url.Values{}
+	vals.Set("target_name", "prod-logs")
+	tgt, am, target, err := resolveProbeTarget(cfg, vals)
+	if err != nil {
+		t.Fatalf("expected success, got err=%v", err)
+	}
+	if tgt != "https://logs.example.com:9200" || am == nil || am.Type != "userpass" || target == nil {
+		t.Fatalf("unexpected resolution: target=%s module=%+v config=%+v", tgt, am, target)
+	}
+
+	vals.Set("target_name", "missing")
+	if _, _, _, err := resolveProbeTarget(cfg, vals); err != errTargetNotFound {
+		t.Fatalf("expected target not found error, got %v", err)
+	}
+
+	vals.Set("target_name", "prod-logs")
+	vals.Set("target", "http://localhost:9200")
+	if _, _, _, err := resolveProbeTarget(cfg, vals); err != errAmbiguousTarget {
+		t.Fatalf("expected ambiguous target error, got %v", err)
+	}
+
+	// without target_name the target parameter is used
+	vals = url.Values{}
+	vals.Set("target", "localhost:9200")
+	tgt, _, target, err = resolveProbeTarget(cfg, vals)
+	if err != nil || tgt != "http://localhost:9200" || target != nil {
+		t.Fatalf("expected plain target, got target=%s config=%+v err=%v", tgt, target, err)
+	}
+}
+
+func TestRunnerStatesFor(t *testing.T) {
+	now := time.Now()
+	prod := probeStateKey(url.Values{"target": {"http://es:9200"}, "auth_module": {"prod"}})
+	other := probeStateKey(url.Values{"target": {"http://es:9200"}, "auth_module": {"other"}})
+	named := probeStateKey(url.Values{"target_name": {"prod"}})
+
+	states := runnerStatesFor(prod, now)
+	if runnerStatesFor(prod, now.Add(time.Minute)) != states {
+		t.Error("expected probes of the same target and auth module to share states")
+	}
+	if runnerStatesFor(other, now) == states || runnerStatesFor(named, now) == states {
+		t.Error("expected probes of other targets or auth modules to have their own states")
+	}
+	if runnerStatesFor(prod, now.Add(time.Minute+probeStateTTL+time.Second)) == states {
+		t.Error("expected the states of a target not probed for the TTL to be dropped")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/prometheus-community/elasticsearch_exporter/collector"
+	"github.com/prometheus-community/elasticsearch_exporter/config"
+)
+
+// loadConfig loads the config file and checks that the collectors it names
+// exist, which the config package cannot do itself.
+func loadConfig(path string) (*config.Config, error) {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	for name := range cfg.Collectors {
+		if err := collector.CheckCollectors([]string{name}); err != nil {
+			return nil, fmt.Errorf("collectors: %w", err)
+		}
+	}
+	for name, t := range cfg.Targets {
+		if err := collector.CheckCollectors(t.Collectors); err != nil {
+			return nil, fmt.Errorf("target %s: %w", name, err)
+		}
+	}
+	return cfg, nil
+}
+
+// configReloader holds the configuration loaded from the config file and
+// replaces it atomically on reload. A configuration that fails to load or
+// validate is discarded and the previous one stays in use.
+type configReloader struct {
+	path   string
+	logger *slog.Logger
+	cfg    atomic.Pointer[config.Config]
+
+	// mu serializes reloads.
+	mu    sync.Mutex
+	hooks []reloadHook
+
+	lastReloadSuccessful  prometheus.Gauge
+	lastReloadSuccessTime prometheus.Gauge
+}
+
+func newConfigReloader(path string, cfg *config.Config, logger *slog.Logger) *configReloader {
+	r := &configReloader{
+		path:   path,
+		logger: logger,
+		lastReloadSuccessful: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: name,
+			Name:      "config_last_reload_successful",
+			Help:      "Whether the last configuration reload attempt was successful.",
+		}),
+		lastReloadSuccessTime: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: name,
+			Name:      "config_last_reload_success_timestamp_seconds",
+			Help:      "Timestamp of the last successful configuration reload.",
+		}),
+	}
+	r.cfg.Store(cfg)
+	r.lastReloadSuccessful.Set(1)
+	r.lastReloadSuccessTime.SetToCurrentTime()
+	return r
+}
+
+// Config returns the current configuration.
+func (r *configReloader) Config() *config.Config {
+	return r.cfg.Load()
+}
+
+// reloadHook checks a configuration that loaded successfully and prepares
+// its changes. It returns a function that applies them, or nil if there is
+// nothing to apply. If it returns an error, the reload fails.
+type reloadHook func(*config.Config) (apply func(), err error)
+
+// OnReload registers f to be called with every configuration that loaded
+// successfully, before it replaces the current one. The changes prepared by
+// the hooks are only applied once all of them accepted the configuration, so
+// a failed reload leaves everything in its current state.
+func (r *configReloader) OnReload(f reloadHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, f)
+}
+
+// Reload loads and validates the config file and, if successful, replaces the
+// current configuration with it.
+func (r *configReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := loadConfig(r.path)
+	var applies []func()
+	for _, hook := range r.hooks {
+		if err != nil {
+			break
+		}
+		var apply func()
+		apply, err = hook(cfg)
+		if apply != nil {
+			applies = append(applies, apply)
+		}
+	}
+	if err != nil {
+		r.lastReloadSuccessful.Set(0)
+		r.logger.Error("failed to reload config file", "file", r.path, "err", err)
+		return err
+	}
+	r.cfg.Store(cfg)
+	for _, apply := range applies {
+		apply()
+	}
+	r.lastReloadSuccessful.Set(1)
+	r.lastReloadSuccessTime.Set(float64(time.Now().Unix()))
+	r.logger.Info("reloaded config file", "file", r.path)
+	return nil
+}
+
+// watchSignals reloads the configuration on every SIGHUP until ctx is done.
+func (r *configReloader) watchSignals(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			_ = r.Reload()
+		}
+	}
+}
+
+// ServeHTTP reloads the configuration on POST requests.
+func (r *configReloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.Reload(); err != nil {
+		http.Error(w, fmt.Sprintf("failed to reload config: %s", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (r *configReloader) Describe(ch chan<- *prometheus.Desc) {
+	r.lastReloadSuccessful.Describe(ch)
+	r.lastReloadSuccessTime.Describe(ch)
+}
+
+// Collect implements the prometheus.Collector interface.
+func (r *configReloader) Collect(ch chan<- prometheus.Metric) {
+	r.lastReloadSuccessful.Collect(ch)
+	r.lastReloadSuccessTime.Collect(ch)
+}
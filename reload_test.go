@@ -0,0 +1,134 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+
+	"github.com/prometheus-community/elasticsearch_exporter/config"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigReloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeConfig(t, path, `auth_modules:
+  old:
+    type: apikey
+    apikey: b2xk`)
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newConfigReloader(path, cfg, promslog.NewNopLogger())
+
+	// valid config is swapped in
+	writeConfig(t, path, `auth_modules:
+  new:
+    type: apikey
+    apikey: bmV3`)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/-/reload", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected reload to succeed, got %d: %s", rec.Code, rec.Body)
+	}
+	if _, ok := r.Config().AuthModules["new"]; !ok {
+		t.Fatalf("expected reloaded config, got %+v", r.Config())
+	}
+	if v := testutil.ToFloat64(r.lastReloadSuccessful); v != 1 {
+		t.Fatalf("expected last reload to be successful, got %v", v)
+	}
+
+	// invalid config keeps the previous one
+	writeConfig(t, path, `auth_modules:
+  broken:
+    type: foobar`)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/-/reload", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected reload to fail, got %d", rec.Code)
+	}
+	if _, ok := r.Config().AuthModules["new"]; !ok {
+		t.Fatalf("expected previous config to be kept, got %+v", r.Config())
+	}
+	if v := testutil.ToFloat64(r.lastReloadSuccessful); v != 0 {
+		t.Fatalf("expected last reload to be unsuccessful, got %v", v)
+	}
+
+	// unknown target collectors are rejected on load
+	writeConfig(t, path, `targets:
+  prod:
+    url: http://localhost:9200
+    collectors: [cluster-health, no-such-collector]`)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/-/reload", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected reload with unknown collector to fail, got %d", rec.Code)
+	}
+	if _, ok := r.Config().AuthModules["new"]; !ok {
+		t.Fatalf("expected previous config to be kept, got %+v", r.Config())
+	}
+
+	// a failing reload hook keeps the previous config and discards the
+	// changes prepared by earlier hooks
+	current := r.Config().AuthModules["new"]
+	clientCfg := clientConfig{
+		tls:         []*config.TLSConfig{current.TLS},
+		authModules: []*config.AuthModule{&current},
+	}
+	client, base, err := newHTTPClient(clientCfg, promslog.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &swappableTransport{}
+	transport.swap(client.Transport, base)
+	hc := &http.Client{Transport: transport}
+	ts := authHeaderServer(t)
+
+	r.OnReload(authModuleReloader("new", current, clientCfg, transport, promslog.NewNopLogger()))
+	r.OnReload(func(*config.Config) (func(), error) { return nil, errors.New("rejected") })
+	writeConfig(t, path, `auth_modules:
+  new:
+    type: apikey
+    apikey: bmV3ZXI=`)
+	if err := r.Reload(); err == nil {
+		t.Fatal("expected reload to fail when a hook fails")
+	}
+	if am := r.Config().AuthModules["new"]; am.APIKey != "bmV3" {
+		t.Fatalf("expected previous config to be kept, got %+v", r.Config())
+	}
+	if got := getBody(t, hc, ts.URL); got != "ApiKey bmV3" {
+		t.Fatalf("expected the transport not to be swapped, got %q", got)
+	}
+
+	// only POST triggers a reload
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/-/reload", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected method not allowed, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+
+// scrapeContext derives the context for a scrape from the incoming request.
+// If Prometheus announces its scrape timeout via the
+// X-Prometheus-Scrape-Timeout-Seconds header, the context gets a deadline of
+// that timeout minus offset, so that Elasticsearch requests are cancelled and
+// the partial result is written before Prometheus gives up on the scrape.
+func scrapeContext(r *http.Request, offset time.Duration) (context.Context, context.CancelFunc, error) {
+	v := r.Header.Get(scrapeTimeoutHeader)
+	if v == "" {
+		ctx, cancel := context.WithCancel(r.Context())
+		return ctx, cancel, nil
+	}
+
+	seconds, err := strconv.ParseFloat(v, 64)
+	if err != nil || seconds <= 0 {
+		return nil, nil, fmt.Errorf("invalid %s header %q", scrapeTimeoutHeader, v)
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > offset {
+		timeout -= offset
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return ctx, cancel, nil
+}
@@ -0,0 +1,66 @@
+// Copyright The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestScrapeContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		offset      time.Duration
+		wantErr     bool
+		hasDeadline bool
+		maxTimeout  time.Duration
+	}{
+		{name: "no header", header: "", offset: 500 * time.Millisecond},
+		{name: "header with offset", header: "10", offset: 500 * time.Millisecond, hasDeadline: true, maxTimeout: 9500 * time.Millisecond},
+		{name: "offset larger than timeout", header: "0.25", offset: 500 * time.Millisecond, hasDeadline: true, maxTimeout: 250 * time.Millisecond},
+		{name: "invalid header", header: "abc", wantErr: true},
+		{name: "negative header", header: "-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/metrics", nil)
+			if tt.header != "" {
+				r.Header.Set(scrapeTimeoutHeader, tt.header)
+			}
+
+			ctx, cancel, err := scrapeContext(r, tt.offset)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok != tt.hasDeadline {
+				t.Fatalf("expected deadline %v, got %v", tt.hasDeadline, ok)
+			}
+			if ok && time.Until(deadline) > tt.maxTimeout {
+				t.Fatalf("expected deadline within %s, got %s", tt.maxTimeout, time.Until(deadline))
+			}
+		})
+	}
+}